@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	_ "time/tzdata" // sync.timezone must resolve on systems without a zone database (e.g. Windows)
 
 	"github.com/Ja-Crispy/git-activity-mirror/pkg/cmd"
 )
@@ -4,9 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/mirror"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/schedule"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // NewConfigCommand creates the config command
@@ -122,3 +130,217 @@ func runConfigValidate(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// minTrailerKeyLength is the shortest accepted sync.trailer_key and
+// sync.name_key
+const minTrailerKeyLength = 16
+
+// loadConfig reads the configuration file in use, expanding ${VAR} references
+// to environment variables (e.g. tokens) before parsing
+func loadConfig() (*Config, error) {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		return nil, fmt.Errorf("no configuration file found - run 'git-activity-mirror init' first")
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(expandEnv(content), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &config, nil
+}
+
+// envReference matches the ${VAR} references loadConfig expands. A bare $VAR
+// is left alone so a $ in commit messages and other values survives.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references in a configuration file with the
+// variables' values, empty for unset ones
+func expandEnv(content []byte) []byte {
+	return envReference.ReplaceAllFunc(content, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(ref[2 : len(ref)-1])))
+	})
+}
+
+// configEnvVars returns the names of the environment variables a
+// configuration file refers to, sorted and without duplicates
+func configEnvVars(content []byte) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range envReference.FindAllSubmatch(content, -1) {
+		name := string(match[1])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks the configuration for missing or conflicting settings
+func (c *Config) Validate() error {
+	if len(c.Sources) == 0 {
+		return fmt.Errorf("no sources configured")
+	}
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("no targets configured")
+	}
+
+	names := make(map[string]bool)
+	for _, source := range c.Sources {
+		if source.Name == "" {
+			return fmt.Errorf("source without a name")
+		}
+		if names[source.Name] {
+			return fmt.Errorf("duplicate platform name: %s", source.Name)
+		}
+		names[source.Name] = true
+
+		if err := validateTemplate(source.CommitMessage); err != nil {
+			return fmt.Errorf("source %s: %w", source.Name, err)
+		}
+	}
+	for _, target := range c.Targets {
+		if target.Name == "" {
+			return fmt.Errorf("target without a name")
+		}
+		if names[target.Name] {
+			return fmt.Errorf("duplicate platform name: %s", target.Name)
+		}
+		names[target.Name] = true
+
+		if target.Mirror.Repository == "" {
+			return fmt.Errorf("target %s: mirror.repository is required", target.Name)
+		}
+		if !platforms.CanMirror(target.platformConfig()) {
+			return fmt.Errorf("target %s: Bitbucket Server can only be a source (set extra.edition: cloud for Bitbucket Cloud)", target.Name)
+		}
+		if err := mirror.ValidateStrategy(target.Mirror.Strategy); err != nil {
+			return fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		// Keyed with anything public, hashed names give away the sources
+		if target.Mirror.Strategy == platforms.StrategyHashed && c.Sync.NameKey == "" {
+			return fmt.Errorf("target %s: the hashed strategy requires sync.name_key", target.Name)
+		}
+		if err := validateTemplate(target.CommitMessage); err != nil {
+			return fmt.Errorf("target %s: %w", target.Name, err)
+		}
+	}
+
+	if err := validateTemplate(c.Sync.CommitMessage); err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	loc, err := loadLocation(c.Sync.Timezone)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	if c.Sync.Schedule != "" {
+		if _, err := schedule.Parse(c.Sync.Schedule, loc); err != nil {
+			return fmt.Errorf("sync.schedule: %w", err)
+		}
+	}
+
+	// The trailer key is what keeps fingerprints from being reversed by
+	// hashing candidate SHAs, so it must not be guessable
+	if c.Sync.TrailerKey != "" && len(c.Sync.TrailerKey) < minTrailerKeyLength {
+		return fmt.Errorf("sync.trailer_key must be at least %d characters", minTrailerKeyLength)
+	}
+	if c.Sync.NameKey != "" && len(c.Sync.NameKey) < minTrailerKeyLength {
+		return fmt.Errorf("sync.name_key must be at least %d characters", minTrailerKeyLength)
+	}
+
+	return nil
+}
+
+// loadLocation resolves sync.timezone: "local" (or empty) is the system
+// zone, anything else must be an IANA zone name such as "America/New_York"
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// validateTemplate checks an optional commit message template
+func validateTemplate(text string) error {
+	_, err := parseTemplate(text)
+	return err
+}
+
+// parseTemplate parses an optional commit message template; an empty one
+// yields nil so the next less specific template applies
+func parseTemplate(text string) (*mirror.MessageTemplate, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return mirror.ParseMessageTemplate(text)
+}
+
+// platformConfig converts a source entry into a platform configuration
+func (s SourceConfig) platformConfig() platforms.PlatformConfig {
+	return platforms.PlatformConfig{
+		Name:     s.Name,
+		Platform: platforms.PlatformType(s.Platform),
+		Host:     s.Host,
+		Auth:     s.Auth.platformAuth(s.Host),
+		Repos:    s.Repositories,
+		Extra:    s.Extra,
+	}
+}
+
+// platformConfig converts a target entry into a platform configuration
+func (t TargetConfig) platformConfig() platforms.PlatformConfig {
+	branch := t.Mirror.Branch
+	if branch == "" {
+		branch = "main"
+	}
+
+	return platforms.PlatformConfig{
+		Name:     t.Name,
+		Platform: platforms.PlatformType(t.Platform),
+		Host:     t.Host,
+		Auth:     t.Auth.platformAuth(t.Host),
+		Mirror: platforms.MirrorConfig{
+			Repository: t.Mirror.Repository,
+			Visibility: t.Mirror.Visibility,
+			Branch:     branch,
+			Strategy:   t.Mirror.Strategy,
+		},
+		Extra: t.Extra,
+	}
+}
+
+func (i IdentitiesConfig) platformIdentities() platforms.Identities {
+	return platforms.Identities{
+		Names:     i.Names,
+		Emails:    i.Emails,
+		Usernames: i.Usernames,
+	}
+}
+
+func (a AuthConfig) platformAuth(host string) platforms.AuthConfig {
+	return platforms.AuthConfig{
+		Type:     platforms.AuthType(a.Type),
+		Token:    a.Token,
+		Username: a.Username,
+		Password: a.Password,
+		SSHKey:   a.SSHKey,
+		Host:     host,
+	}
+}
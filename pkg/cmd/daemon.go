@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/mirror"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/schedule"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// wakeInterval is the longest the daemon sleeps at a time. Timers follow the
+// monotonic clock, which stops while the machine is suspended, so the wall
+// clock is checked against the next run on every wake-up instead.
+const wakeInterval = time.Minute
+
+// NewDaemonCommand creates the daemon command
+func NewDaemonCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run syncs on the configured schedule",
+		Long: `Run in the foreground and sync on the cron schedule in sync.schedule,
+evaluated in sync.timezone.
+
+Runs never overlap: if a sync is still running when the next one is due,
+that tick is skipped. Each run continues from the previous one (like
+sync --since last). Send SIGINT or SIGTERM to stop; a sync in progress is
+cancelled, and the next run picks up what it didn't finish.`,
+		RunE: runDaemon,
+	}
+
+	cmd.Flags().String("since", defaultSyncWindow, "how far back to look for repositories that have never been synced")
+	cmd.Flags().StringSlice("sources", nil, "specific source platforms to sync from")
+	cmd.Flags().StringSlice("targets", nil, "specific target platforms to sync to")
+	cmd.Flags().Bool("run-now", false, "run a sync immediately on startup")
+
+	return cmd
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	verbose := viper.GetBool("verbose")
+	dryRun := viper.GetBool("dry-run")
+
+	sinceStr, _ := cmd.Flags().GetString("since")
+	window, err := parseDuration(sinceStr)
+	if err != nil {
+		return fmt.Errorf("invalid since duration: %w", err)
+	}
+
+	sourceNames, _ := cmd.Flags().GetStringSlice("sources")
+	targetNames, _ := cmd.Flags().GetStringSlice("targets")
+	runNow, _ := cmd.Flags().GetBool("run-now")
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	sched, err := loadSchedule(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	logger.Printf("🕒 Daemon started (schedule %q, timezone %s)", sched, sched.Location())
+
+	tick := func() {
+		// Reload so config edits apply without restarting the daemon
+		config, err := loadConfig()
+		if err != nil {
+			logger.Printf("❌ Sync skipped: %v", err)
+			return
+		}
+
+		logger.Println("🔄 Starting scheduled sync")
+		result, err := syncOnce(ctx, config, sourceNames, targetNames, time.Now().Add(-window), mirror.Options{
+			SkipExisting: true,
+			DryRun:       dryRun,
+			Verbose:      verbose,
+			Incremental:  true,
+		})
+		if err != nil {
+			logger.Printf("❌ Sync finished with errors: %v", err)
+			for _, hint := range hintsFor(err) {
+				logger.Println(hint)
+			}
+			return
+		}
+		logger.Printf("✅ Sync completed (%d commits mirrored)", result.Mirrored)
+	}
+
+	if runNow {
+		tick()
+	}
+
+	for {
+		now := time.Now()
+		next := sched.Next(now)
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never fires", sched)
+		}
+		logger.Printf("⏰ Next sync at %s", next.Format("2006-01-02 15:04 MST"))
+
+		if !sleepUntil(ctx, next) {
+			logger.Println("👋 Daemon stopped")
+			return nil
+		}
+		if missed := countMissed(sched, next, time.Now()); missed > 0 {
+			logger.Printf("⏭️  Missed %d scheduled run(s) while asleep, syncing once", missed)
+		}
+
+		// Syncs run inline, so a long run simply delays the next Next() call
+		// and any ticks it overlapped are skipped rather than queued
+		tick()
+		if missed := countMissed(sched, next, time.Now()); missed > 0 {
+			logger.Printf("⏭️  Skipped %d scheduled run(s) while the sync was running", missed)
+		}
+	}
+}
+
+// loadSchedule parses sync.schedule in the configured time zone
+func loadSchedule(config *Config) (*schedule.Schedule, error) {
+	if config.Sync.Schedule == "" {
+		return nil, fmt.Errorf("sync.schedule is not set")
+	}
+
+	loc, err := loadLocation(config.Sync.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("sync: %w", err)
+	}
+
+	sched, err := schedule.Parse(config.Sync.Schedule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("sync.schedule: %w", err)
+	}
+	return sched, nil
+}
+
+// sleepUntil waits until the wall clock reaches t, waking up at least every
+// wakeInterval to check. It returns false if ctx is done first.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	for {
+		// Round(0) drops the monotonic reading so the comparison uses the
+		// wall clock, which keeps counting through suspend
+		wait := t.Sub(time.Now().Round(0))
+		if wait <= 0 {
+			return true
+		}
+		if wait > wakeInterval {
+			wait = wakeInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// countMissed counts schedule ticks strictly between from and to
+func countMissed(sched *schedule.Schedule, from, to time.Time) int {
+	missed := 0
+	for t := sched.Next(from); !t.IsZero() && t.Before(to); t = sched.Next(t) {
+		missed++
+	}
+	return missed
+}
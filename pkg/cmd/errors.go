@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/mirror"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+// errorHints lists what to do about each kind of failure, in the order they
+// are reported
+var errorHints = []struct {
+	err  error
+	hint string
+}{
+	{platforms.ErrInvalidAuth, "Authentication failed - check the tokens in your config and that they haven't expired"},
+	{platforms.ErrPermissionDenied, "Permission denied - the token needs read access to sources and write access to mirror repositories"},
+	{platforms.ErrRepositoryNotFound, "Repository not found - check the repository names, and that the token can see them"},
+	{platforms.ErrRateLimit, "Rate limited - try again later, or allow longer waits with sync.rate_limit_wait"},
+	{platforms.ErrNoIdentity, "No identity - list your names and emails under identities so only your commits are mirrored"},
+	{platforms.ErrAlreadyExists, "Already exists - pick another mirror.repository name, or make sure the token owns it"},
+	{mirror.ErrLedgerLocked, "Another run is in progress - wait for it to finish"},
+	{context.DeadlineExceeded, "Timed out - raise --timeout or sync.timeout for slow hosts"},
+}
+
+// hintsFor returns a hint for each kind of failure found in err
+func hintsFor(err error) []string {
+	var hints []string
+	for _, h := range errorHints {
+		if errors.Is(err, h.err) {
+			hints = append(hints, "💡 "+h.hint)
+		}
+	}
+	return hints
+}
@@ -113,26 +113,42 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 // Configuration structures
 type Config struct {
-	Version int            `yaml:"version"`
-	Sources []SourceConfig `yaml:"sources"`
-	Targets []TargetConfig `yaml:"targets"`
-	Sync    SyncConfig     `yaml:"sync"`
+	Version    int              `yaml:"version"`
+	Identities IdentitiesConfig `yaml:"identities,omitempty"`
+	Sources    []SourceConfig   `yaml:"sources"`
+	Targets    []TargetConfig   `yaml:"targets"`
+	Sync       SyncConfig       `yaml:"sync"`
+	Webhook    WebhookConfig    `yaml:"webhook,omitempty"`
+}
+
+// IdentitiesConfig lists the names, emails and platform usernames whose
+// commits are yours
+type IdentitiesConfig struct {
+	Names     []string `yaml:"names,omitempty"`
+	Emails    []string `yaml:"emails,omitempty"`
+	Usernames []string `yaml:"usernames,omitempty"`
 }
 
 type SourceConfig struct {
-	Name         string     `yaml:"name"`
-	Platform     string     `yaml:"platform"`
-	Host         string     `yaml:"host,omitempty"`
-	Auth         AuthConfig `yaml:"auth"`
-	Repositories []string   `yaml:"repositories,omitempty"`
+	Name          string                 `yaml:"name"`
+	Platform      string                 `yaml:"platform"`
+	Host          string                 `yaml:"host,omitempty"`
+	Auth          AuthConfig             `yaml:"auth"`
+	Repositories  []string               `yaml:"repositories,omitempty"`
+	Aliases       map[string]string      `yaml:"aliases,omitempty"`
+	CommitMessage string                 `yaml:"commit_message,omitempty"`
+	Mailmap       []string               `yaml:"mailmap,omitempty"`
+	Extra         map[string]interface{} `yaml:"extra,omitempty"`
 }
 
 type TargetConfig struct {
-	Name     string       `yaml:"name"`
-	Platform string       `yaml:"platform"`
-	Host     string       `yaml:"host,omitempty"`
-	Auth     AuthConfig   `yaml:"auth"`
-	Mirror   MirrorConfig `yaml:"mirror"`
+	Name          string                 `yaml:"name"`
+	Platform      string                 `yaml:"platform"`
+	Host          string                 `yaml:"host,omitempty"`
+	Auth          AuthConfig             `yaml:"auth"`
+	Mirror        MirrorConfig           `yaml:"mirror"`
+	CommitMessage string                 `yaml:"commit_message,omitempty"`
+	Extra         map[string]interface{} `yaml:"extra,omitempty"`
 }
 
 type AuthConfig struct {
@@ -147,10 +163,22 @@ type MirrorConfig struct {
 	Repository string `yaml:"repository"`
 	Visibility string `yaml:"visibility"`
 	Branch     string `yaml:"branch,omitempty"`
+	Strategy   string `yaml:"strategy,omitempty"`
 }
 
 type SyncConfig struct {
 	Schedule      string `yaml:"schedule"`
 	Timezone      string `yaml:"timezone"`
 	CommitMessage string `yaml:"commit_message"`
+	TrailerKey    string `yaml:"trailer_key,omitempty"`
+	NameKey       string `yaml:"name_key,omitempty"`
+	Timeout       string `yaml:"timeout,omitempty"`
+	RateLimitWait string `yaml:"rate_limit_wait,omitempty"`
+}
+
+type WebhookConfig struct {
+	Listen       string `yaml:"listen,omitempty"`
+	Path         string `yaml:"path,omitempty"`
+	GitHubSecret string `yaml:"github_secret,omitempty"`
+	GitLabToken  string `yaml:"gitlab_token,omitempty"`
 }
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,10 +31,12 @@ allowing you to maintain an accurate contribution graph across all platforms.`,
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.git-activity-mirror/config.yaml)")
 	rootCmd.PersistentFlags().Bool("verbose", false, "verbose output")
 	rootCmd.PersistentFlags().Bool("dry-run", false, "show what would be done without making changes")
+	rootCmd.PersistentFlags().String("timeout", "", "limit on each API request or git command, e.g. 2m (default: sync.timeout or "+defaultRequestTimeout+")")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	_ = viper.BindPFlag("dry-run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	_ = viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
 
 	// Initialize config
 	cobra.OnInitialize(func() { initConfig(cfgFile) })
@@ -40,12 +45,20 @@ allowing you to maintain an accurate contribution graph across all platforms.`,
 	rootCmd.AddCommand(NewInitCommand())
 	rootCmd.AddCommand(NewSyncCommand())
 	rootCmd.AddCommand(NewImportCommand())
+	rootCmd.AddCommand(NewDaemonCommand())
+	rootCmd.AddCommand(NewScheduleCommand())
+	rootCmd.AddCommand(NewServeCommand())
 	rootCmd.AddCommand(NewStatusCommand())
 	rootCmd.AddCommand(NewConfigCommand())
 
 	return rootCmd
 }
 
+// interruptContext returns a context cancelled on SIGINT or SIGTERM
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
 // initConfig reads in config file and ENV variables
 func initConfig(cfgFile string) {
 	if cfgFile != "" {
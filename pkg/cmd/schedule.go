@@ -0,0 +1,504 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/schedule"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// unitName is the base name of the generated systemd units
+	unitName = "git-activity-mirror"
+
+	// cronMarker tags the crontab line this tool manages
+	cronMarker = "# managed by git-activity-mirror"
+
+	// envFileName is the file in the state directory holding the environment
+	// variables the configuration refers to, which neither systemd nor cron
+	// pass on to the jobs they start
+	envFileName = "schedule.env"
+)
+
+// NewScheduleCommand creates the schedule command
+func NewScheduleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage system scheduling of syncs",
+		Long: `Install sync.schedule into the system scheduler, as a systemd user timer
+where available or as a crontab entry otherwise.`,
+	}
+
+	cmd.PersistentFlags().String("method", "auto", "scheduler to use: auto, systemd or cron")
+
+	cmd.AddCommand(NewScheduleShowCommand())
+	cmd.AddCommand(NewScheduleInstallCommand())
+	cmd.AddCommand(NewScheduleUninstallCommand())
+
+	return cmd
+}
+
+// NewScheduleShowCommand creates the schedule show subcommand
+func NewScheduleShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the generated scheduler entries",
+		Long:  `Show the systemd units or crontab line that install would write, and whether they are installed.`,
+		RunE:  runScheduleShow,
+	}
+}
+
+// NewScheduleInstallCommand creates the schedule install subcommand
+func NewScheduleInstallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install",
+		Short: "Install the sync schedule",
+		Long:  `Write and enable a systemd user timer, or add a crontab entry, that runs sync on sync.schedule.`,
+		RunE:  runScheduleInstall,
+	}
+}
+
+// NewScheduleUninstallCommand creates the schedule uninstall subcommand
+func NewScheduleUninstallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the sync schedule",
+		Long:  `Disable and remove the systemd user timer, or the crontab entry, written by install.`,
+		RunE:  runScheduleUninstall,
+	}
+}
+
+// scheduledJob is everything needed to render scheduler entries
+type scheduledJob struct {
+	schedule   *schedule.Schedule
+	calendars  []string // OnCalendar= expressions, for systemd only
+	binary     string
+	configFile string
+
+	// envFile holds env, the configuration's environment variables; both are
+	// empty when it refers to none. unset lists those missing right now.
+	envFile string
+	env     []string
+	unset   []string
+}
+
+func runScheduleShow(cmd *cobra.Command, args []string) error {
+	job, method, err := loadScheduledJob(cmd)
+	if err != nil {
+		return err
+	}
+
+	if job.envFile != "" {
+		fmt.Printf("📁 %s (mode 0600)\n\n", job.envFile)
+		for _, line := range job.env {
+			name, _, _ := strings.Cut(line, "=")
+			fmt.Printf("%s=...\n", name)
+		}
+		fmt.Println()
+	}
+	job.warnUnset()
+
+	switch method {
+	case "systemd":
+		dir, err := systemdUserDir()
+		if err != nil {
+			return err
+		}
+
+		service, timer := job.serviceUnit(), job.timerUnit()
+		fmt.Printf("📁 %s\n\n%s\n", filepath.Join(dir, unitName+".service"), service)
+		fmt.Printf("📁 %s\n\n%s\n", filepath.Join(dir, unitName+".timer"), timer)
+
+		if _, err := os.Stat(filepath.Join(dir, unitName+".timer")); err == nil {
+			fmt.Println("✅ Installed")
+		} else {
+			fmt.Println("⚪ Not installed")
+		}
+	case "cron":
+		fmt.Printf("📝 crontab entry:\n\n%s\n\n", job.cronLine())
+
+		current, err := readCrontab()
+		if err != nil {
+			return err
+		}
+		if strings.Contains(current, cronMarker) {
+			fmt.Println("✅ Installed")
+		} else {
+			fmt.Println("⚪ Not installed")
+		}
+	}
+
+	return nil
+}
+
+func runScheduleInstall(cmd *cobra.Command, args []string) error {
+	job, method, err := loadScheduledJob(cmd)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool("dry-run") {
+		fmt.Println("🧪 Dry run mode - no changes will be made")
+		return runScheduleShow(cmd, args)
+	}
+
+	if job.envFile != "" {
+		if err := os.MkdirAll(filepath.Dir(job.envFile), 0700); err != nil {
+			return fmt.Errorf("failed to create state directory: %w", err)
+		}
+		// The file holds tokens, so it must not be readable by others even
+		// if it already exists with looser permissions
+		if err := os.WriteFile(job.envFile, []byte(strings.Join(job.env, "\n")+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write environment file: %w", err)
+		}
+		if err := os.Chmod(job.envFile, 0600); err != nil {
+			return fmt.Errorf("failed to write environment file: %w", err)
+		}
+		fmt.Printf("🔑 Saved %d environment variables for scheduled runs to %s\n", len(job.env), job.envFile)
+	}
+	job.warnUnset()
+
+	switch method {
+	case "systemd":
+		dir, err := systemdUserDir()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create systemd user directory: %w", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, unitName+".service"), []byte(job.serviceUnit()), 0644); err != nil {
+			return fmt.Errorf("failed to write service unit: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, unitName+".timer"), []byte(job.timerUnit()), 0644); err != nil {
+			return fmt.Errorf("failed to write timer unit: %w", err)
+		}
+
+		if err := systemctl("daemon-reload"); err != nil {
+			return err
+		}
+		if err := systemctl("enable", "--now", unitName+".timer"); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Installed systemd timer %s.timer (%s)\n", unitName, strings.Join(job.calendars, "; "))
+		fmt.Printf("   Check it with: systemctl --user list-timers %s.timer\n", unitName)
+	case "cron":
+		current, err := readCrontab()
+		if err != nil {
+			return err
+		}
+
+		lines := withoutManagedLines(current)
+		lines = append(lines, job.cronLine())
+		if err := writeCrontab(strings.Join(lines, "\n") + "\n"); err != nil {
+			return err
+		}
+
+		fmt.Println("✅ Installed crontab entry:")
+		fmt.Printf("   %s\n", job.cronLine())
+		if job.schedule.Location() != time.Local {
+			fmt.Printf("⚠️  cron runs in the system time zone, not %s\n", job.schedule.Location())
+		}
+	}
+
+	return nil
+}
+
+func runScheduleUninstall(cmd *cobra.Command, args []string) error {
+	method, err := scheduleMethod(cmd)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool("dry-run") {
+		fmt.Printf("🧪 Dry run mode - would remove the %s schedule\n", method)
+		return nil
+	}
+
+	switch method {
+	case "systemd":
+		dir, err := systemdUserDir()
+		if err != nil {
+			return err
+		}
+
+		// The timer may already be gone; removing the files is what matters
+		_ = systemctl("disable", "--now", unitName+".timer")
+
+		for _, name := range []string{unitName + ".timer", unitName + ".service"} {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+		}
+
+		if err := systemctl("daemon-reload"); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Removed systemd timer %s.timer\n", unitName)
+	case "cron":
+		current, err := readCrontab()
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(current, cronMarker) {
+			fmt.Println("⚪ No crontab entry installed")
+			return nil
+		}
+
+		lines := withoutManagedLines(current)
+		content := ""
+		if len(lines) > 0 {
+			content = strings.Join(lines, "\n") + "\n"
+		}
+		if err := writeCrontab(content); err != nil {
+			return err
+		}
+		fmt.Println("✅ Removed crontab entry")
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, envFileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove environment file: %w", err)
+	}
+
+	return nil
+}
+
+// loadScheduledJob resolves the schedule, binary and config file to install
+func loadScheduledJob(cmd *cobra.Command) (*scheduledJob, string, error) {
+	method, err := scheduleMethod(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	sched, err := loadSchedule(config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve binary path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+
+	configFile, err := filepath.Abs(viper.ConfigFileUsed())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+
+	job := &scheduledJob{
+		schedule:   sched,
+		binary:     binary,
+		configFile: configFile,
+	}
+	if method == "systemd" {
+		if job.calendars, err = sched.OnCalendar(); err != nil {
+			return nil, "", fmt.Errorf("sync.schedule: %w", err)
+		}
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	if names := configEnvVars(content); len(names) > 0 {
+		dir, err := stateDir()
+		if err != nil {
+			return nil, "", err
+		}
+		job.envFile = filepath.Join(dir, envFileName)
+
+		for _, name := range names {
+			if value, ok := os.LookupEnv(name); ok {
+				job.env = append(job.env, name+"="+envQuote(value))
+			} else {
+				job.unset = append(job.unset, name)
+			}
+		}
+	}
+
+	return job, method, nil
+}
+
+// warnUnset points out configuration variables scheduled runs won't see
+func (j *scheduledJob) warnUnset() {
+	for _, name := range j.unset {
+		fmt.Printf("⚠️  $%s is used by the configuration but not set - scheduled runs won't see it either\n", name)
+	}
+}
+
+// scheduleMethod picks the scheduler from --method, preferring systemd
+func scheduleMethod(cmd *cobra.Command) (string, error) {
+	method, _ := cmd.Flags().GetString("method")
+
+	switch method {
+	case "systemd", "cron":
+		return method, nil
+	case "auto":
+		if runtime.GOOS == "linux" {
+			if _, err := exec.LookPath("systemctl"); err == nil {
+				return "systemd", nil
+			}
+		}
+		if _, err := exec.LookPath("crontab"); err == nil {
+			return "cron", nil
+		}
+		return "", fmt.Errorf("no supported scheduler found (systemd or crontab) - run 'git-activity-mirror daemon' instead")
+	default:
+		return "", fmt.Errorf("unknown scheduling method %q (expected auto, systemd or cron)", method)
+	}
+}
+
+// serviceUnit renders the oneshot service that runs a sync
+func (j *scheduledJob) serviceUnit() string {
+	return fmt.Sprintf(`[Unit]
+Description=git-activity-mirror sync
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+%sExecStart=%s sync --since last --config %s
+`, j.environmentFile(), systemdQuote(j.binary), systemdQuote(j.configFile))
+}
+
+// environmentFile renders the EnvironmentFile= line of the service, if any
+func (j *scheduledJob) environmentFile() string {
+	if j.envFile == "" {
+		return ""
+	}
+	return "EnvironmentFile=" + strings.ReplaceAll(j.envFile, "%", "%%") + "\n"
+}
+
+// timerUnit renders the timer that starts the service on the schedule.
+// Persistent= catches up on runs missed while the machine was off, which
+// --since last turns into exactly the missing history.
+func (j *scheduledJob) timerUnit() string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=git-activity-mirror sync schedule (%s)\n", j.schedule)
+	b.WriteString("\n[Timer]\n")
+	for _, calendar := range j.calendars {
+		fmt.Fprintf(&b, "OnCalendar=%s\n", calendar)
+	}
+	b.WriteString("Persistent=true\n")
+	b.WriteString("\n[Install]\nWantedBy=timers.target\n")
+	return b.String()
+}
+
+// cronLine renders the crontab entry running a sync, exporting the variables
+// in the environment file first
+func (j *scheduledJob) cronLine() string {
+	var env string
+	if j.envFile != "" {
+		env = fmt.Sprintf("set -a; . %s; set +a; ", cronQuote(j.envFile))
+	}
+	return fmt.Sprintf("%s %s%s sync --since last --config %s %s",
+		j.schedule, env, cronQuote(j.binary), cronQuote(j.configFile), cronMarker)
+}
+
+// systemdUserDir returns the directory for systemd user units
+func systemdUserDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// readCrontab returns the current user's crontab, empty if there is none
+func readCrontab() (string, error) {
+	var stderr bytes.Buffer
+	c := exec.Command("crontab", "-l")
+	c.Stderr = &stderr
+
+	out, err := c.Output()
+	if err != nil {
+		// crontab -l exits non-zero when the user has no crontab yet
+		if strings.Contains(strings.ToLower(stderr.String()), "no crontab") {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read crontab: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+func writeCrontab(content string) error {
+	c := exec.Command("crontab", "-")
+	c.Stdin = strings.NewReader(content)
+
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write crontab: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// withoutManagedLines drops the line this tool manages from a crontab
+func withoutManagedLines(crontab string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(crontab, "\n"), "\n") {
+		if line == "" && len(lines) == 0 {
+			continue
+		}
+		if strings.Contains(line, cronMarker) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// systemdQuote quotes an ExecStart= argument; % starts a specifier in units
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "%", "%%")
+	return `"` + s + `"`
+}
+
+// envQuote single-quotes a value in the environment file, which is read both
+// by systemd's EnvironmentFile= and by sh
+func envQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// cronQuote single-quotes a shell word; % means newline to cron
+func cronQuote(s string) string {
+	s = strings.ReplaceAll(s, "'", `'\''`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return "'" + s + "'"
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/schedule"
+)
+
+// testJob returns a scheduled job whose paths need quoting everywhere
+func testJob(t *testing.T) *scheduledJob {
+	t.Helper()
+
+	sched, err := schedule.Parse("0 18 * * mon-fri", time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendars, err := sched.OnCalendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &scheduledJob{
+		schedule:   sched,
+		calendars:  calendars,
+		binary:     "/opt/my tools/git-activity-mirror",
+		configFile: "/home/jane/100% sure/it's config.yaml",
+		envFile:    "/home/jane/state 50%/schedule.env",
+		env:        []string{"GITHUB_TOKEN=" + envQuote("ghp_x'y%z")},
+	}
+}
+
+func TestServiceUnit(t *testing.T) {
+	unit := testJob(t).serviceUnit()
+
+	for _, want := range []string{
+		`EnvironmentFile=/home/jane/state 50%%/schedule.env` + "\n",
+		`ExecStart="/opt/my tools/git-activity-mirror" sync --since last --config "/home/jane/100%% sure/it's config.yaml"` + "\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("service unit lacks %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestTimerUnit(t *testing.T) {
+	unit := testJob(t).timerUnit()
+
+	want := "OnCalendar=Mon,Tue,Wed,Thu,Fri *-*-* 18:00:00\nPersistent=true\n"
+	if !strings.Contains(unit, want) {
+		t.Errorf("timer unit lacks %q:\n%s", want, unit)
+	}
+}
+
+func TestCronLine(t *testing.T) {
+	job := testJob(t)
+
+	want := `0 18 * * mon-fri set -a; . '/home/jane/state 50\%/schedule.env'; set +a; ` +
+		`'/opt/my tools/git-activity-mirror' sync --since last --config '/home/jane/100\% sure/it'\''s config.yaml' ` +
+		cronMarker
+	if got := job.cronLine(); got != want {
+		t.Errorf("cronLine() =\n%s\nwant\n%s", got, want)
+	}
+
+	job.envFile, job.env = "", nil
+	if got := job.cronLine(); strings.Contains(got, "set -a") {
+		t.Errorf("cronLine() without variables = %s", got)
+	}
+}
+
+// TestQuotingThroughShell runs quoted words through sh, after undoing the %
+// escapes the way cron does, to check they come back unchanged
+func TestQuotingThroughShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	for _, value := range []string{
+		"/plain/path",
+		"/path with spaces/config.yaml",
+		"/100%/it's $HOME `x` \\n \"quoted\"",
+	} {
+		for name, quoted := range map[string]string{
+			"cron": strings.ReplaceAll(cronQuote(value), `\%`, "%"),
+			"env":  envQuote(value),
+		} {
+			out, err := exec.Command("sh", "-c", "printf '%s' "+quoted).Output()
+			if err != nil {
+				t.Fatalf("sh: %v", err)
+			}
+			if string(out) != value {
+				t.Errorf("%s quoting of %q reads back as %q", name, value, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/mirror"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/webhook"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// pushQueueSize is how many verified pushes may wait for the worker
+	pushQueueSize = 100
+
+	// ledgerRetries and ledgerRetryDelay bound how long a push waits for a
+	// concurrent sync to release the ledger
+	ledgerRetries    = 10
+	ledgerRetryDelay = 30 * time.Second
+)
+
+// NewServeCommand creates the serve command
+func NewServeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Receive push webhooks and mirror commits in real time",
+		Long: `Run an HTTP server that accepts GitHub "push" and GitLab "Push Hook" webhooks
+and mirrors the pushed commits right away.
+
+GitHub deliveries are verified against webhook.github_secret using the
+X-Hub-Signature-256 header, GitLab deliveries against webhook.gitlab_token
+using the X-Gitlab-Token header. Pushes to a repository's default branch are
+matched to configured sources by platform, host and repository, and mirrored
+one at a time. When a payload doesn't list every pushed commit, the
+repository is synced from the API instead.`,
+		RunE: runServe,
+	}
+
+	cmd.Flags().String("listen", "", "address to listen on (default: webhook.listen or :8080)")
+
+	return cmd
+}
+
+// pushJob is a verified push waiting to be mirrored
+type pushJob struct {
+	source  string
+	event   webhook.PushEvent
+	retries int
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	verbose := viper.GetBool("verbose")
+	dryRun := viper.GetBool("dry-run")
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	listen, _ := cmd.Flags().GetString("listen")
+	if listen == "" {
+		listen = config.Webhook.Listen
+	}
+	if listen == "" {
+		listen = ":8080"
+	}
+	webhookPath := config.Webhook.Path
+	if webhookPath == "" {
+		webhookPath = "/webhook"
+	}
+
+	if config.Webhook.GitHubSecret == "" && config.Webhook.GitLabToken == "" {
+		return fmt.Errorf("no webhook secrets configured - set webhook.github_secret and/or webhook.gitlab_token")
+	}
+
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	queue := make(chan pushJob, pushQueueSize)
+
+	handler := &webhook.Handler{
+		GitHubSecret: config.Webhook.GitHubSecret,
+		GitLabToken:  config.Webhook.GitLabToken,
+		Logf:         logger.Printf,
+		OnPush: func(event webhook.PushEvent) error {
+			source, ok := findWebhookSource(config, event)
+			if !ok {
+				logger.Printf("⏭️  Ignoring %s push to %s: no matching source", event.Platform, event.Repository)
+				return nil
+			}
+			if !event.OnDefaultBranch() {
+				logger.Printf("⏭️  Ignoring push to %s %s: not the default branch", event.Repository, event.Ref)
+				return nil
+			}
+			if len(event.Commits) == 0 {
+				return nil
+			}
+
+			select {
+			case queue <- pushJob{source: source, event: event}:
+				if event.Truncated {
+					logger.Printf("📥 Queued sync of %s (%s): the push lists only %d commits", event.Repository, source, len(event.Commits))
+				} else {
+					logger.Printf("📥 Queued %d commits pushed to %s (%s)", len(event.Commits), event.Repository, source)
+				}
+				return nil
+			default:
+				return fmt.Errorf("push queue is full, try again later")
+			}
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(webhookPath, handler)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for job := range queue {
+			mirrorPush(ctx, logger, config, job, mirror.Options{
+				SkipExisting: true,
+				DryRun:       dryRun,
+				Verbose:      verbose,
+			})
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	logger.Printf("🌐 Listening for webhooks on %s%s", listen, webhookPath)
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			close(queue)
+			<-done
+			return fmt.Errorf("webhook server failed: %w", err)
+		}
+	case <-ctx.Done():
+	}
+	// A second signal kills the process instead of waiting for the queue
+	stop()
+
+	// Stop accepting deliveries, then let the worker finish what was queued
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Printf("⚠️  Server shutdown: %v", err)
+	}
+	close(queue)
+	<-done
+
+	logger.Println("👋 Webhook server stopped")
+	return nil
+}
+
+// mirrorPush mirrors one queued push. If a sync holds the ledger, the push
+// is retried later rather than dropped.
+func mirrorPush(ctx context.Context, logger *log.Logger, config *Config, job pushJob, opts mirror.Options) {
+	ledger, err := openLedger()
+	if errors.Is(err, mirror.ErrLedgerLocked) && job.retries < ledgerRetries && ctx.Err() == nil {
+		job.retries++
+		logger.Printf("⏳ Ledger busy, retrying push to %s in %s", job.event.Repository, ledgerRetryDelay)
+		select {
+		case <-time.After(ledgerRetryDelay):
+			mirrorPush(ctx, logger, config, job, opts)
+		case <-ctx.Done():
+			logger.Printf("❌ Dropped push to %s: shutting down", job.event.Repository)
+		}
+		return
+	}
+	if err != nil {
+		logger.Printf("❌ Push to %s not mirrored: %v", job.event.Repository, err)
+		return
+	}
+	defer ledger.Close()
+
+	opts.Ledger = ledger
+	if job.event.Truncated {
+		// Fetch the whole push from the API, continuing from the
+		// repository's cursors like sync --since last
+		if opts.Cursors, err = loadCursors(); err != nil {
+			logger.Printf("❌ Push to %s not mirrored: %v", job.event.Repository, err)
+			return
+		}
+		opts.Incremental = true
+		config = withOnlyRepository(config, job.source, job.event.Repository)
+	}
+
+	engine, err := buildEngine(config, []string{job.source}, nil, opts)
+	if err != nil {
+		logger.Printf("❌ Push to %s not mirrored: %v", job.event.Repository, err)
+		return
+	}
+
+	// Queued pushes are finished on shutdown, so only the request timeout
+	// bounds them
+	var result mirror.Result
+	if job.event.Truncated {
+		result, err = engine.Sync(context.WithoutCancel(ctx), truncatedPushSince(job.event))
+	} else {
+		result, err = engine.MirrorPushed(context.WithoutCancel(ctx), job.source, job.event.Commits)
+	}
+	if err != nil {
+		logger.Printf("❌ Push to %s mirrored with errors: %v", job.event.Repository, err)
+		for _, hint := range hintsFor(err) {
+			logger.Println(hint)
+		}
+		return
+	}
+	logger.Printf("✅ Push to %s: %d commits mirrored, %d skipped, %d by others excluded", job.event.Repository, result.Mirrored, result.Skipped, result.Excluded)
+}
+
+// truncatedPushSince returns how far back to look for the commits of a push
+// whose payload left some out, for repositories without a cursor: the
+// default sync window, or further if the listed commits are older
+func truncatedPushSince(event webhook.PushEvent) time.Time {
+	window, _ := parseDuration(defaultSyncWindow)
+	since := time.Now().Add(-window)
+	for _, commit := range event.Commits {
+		if commit.Date.Before(since) {
+			since = commit.Date
+		}
+	}
+	return since
+}
+
+// withOnlyRepository returns a copy of config in which the named source
+// syncs just one repository
+func withOnlyRepository(config *Config, source, repository string) *Config {
+	limited := *config
+	limited.Sources = append([]SourceConfig(nil), config.Sources...)
+	for i := range limited.Sources {
+		if limited.Sources[i].Name == source {
+			limited.Sources[i].Repositories = []string{repository}
+		}
+	}
+	return &limited
+}
+
+// findWebhookSource returns the configured source a push belongs to: same
+// platform and host, and the repository is listed (by full or short name).
+// Failing that, the first such source listing no repositories at all takes
+// it.
+func findWebhookSource(config *Config, event webhook.PushEvent) (string, bool) {
+	var fallback string
+	for _, source := range config.Sources {
+		if platforms.PlatformType(source.Platform) != event.Platform {
+			continue
+		}
+		if event.Host != "" && sourceHost(source) != event.Host {
+			continue
+		}
+		if len(source.Repositories) == 0 {
+			if fallback == "" {
+				fallback = source.Name
+			}
+			continue
+		}
+		for _, repo := range source.Repositories {
+			if repo == event.Repository || repo == path.Base(event.Repository) {
+				return source.Name, true
+			}
+		}
+	}
+	return fallback, fallback != ""
+}
+
+// sourceHost returns the lower case host name a source's platform is served
+// from. Hosts may be configured with or without a scheme.
+func sourceHost(source SourceConfig) string {
+	host := source.Host
+	if host == "" {
+		switch platforms.PlatformType(source.Platform) {
+		case platforms.PlatformGitHub:
+			host = "github.com"
+		case platforms.PlatformGitLab:
+			host = "gitlab.com"
+		}
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
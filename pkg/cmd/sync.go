@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/mirror"
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// defaultSyncWindow is how far back sync looks when no --since is given
+const defaultSyncWindow = "24h"
+
+// defaultRequestTimeout limits each API request and git command when neither
+// --timeout nor sync.timeout is set
+const defaultRequestTimeout = "5m"
+
 // NewSyncCommand creates the sync command
 func NewSyncCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -16,11 +28,12 @@ func NewSyncCommand() *cobra.Command {
 		Long: `Synchronize recent commits from source platforms to target platforms.
 
 By default, this will sync commits from the last 24 hours. You can specify
-a different time range using the --since flag.`,
+a different time range using the --since flag, or use --since last to fetch
+exactly what is new in each repository since the previous successful run.`,
 		RunE: runSync,
 	}
 
-	cmd.Flags().String("since", "24h", "sync commits since this duration (e.g., 24h, 7d, 1w, 3mo, 1y)")
+	cmd.Flags().String("since", defaultSyncWindow, "sync commits since this duration (e.g., 24h, 7d, 1w, 3mo, 1y), or \"last\" to continue from the previous run")
 	cmd.Flags().StringSlice("sources", nil, "specific source platforms to sync from")
 	cmd.Flags().StringSlice("targets", nil, "specific target platforms to sync to")
 	cmd.Flags().Bool("force", false, "force sync even if commits already exist")
@@ -36,8 +49,13 @@ func runSync(cmd *cobra.Command, args []string) error {
 		fmt.Println("🔄 Starting sync operation...")
 	}
 
-	// Parse since duration
+	// Parse since duration; "last" picks up from each repository's cursor and
+	// falls back to the default window for repositories never synced before
 	sinceStr, _ := cmd.Flags().GetString("since")
+	incremental := sinceStr == "last"
+	if incremental {
+		sinceStr = defaultSyncWindow
+	}
 	since, err := parseDuration(sinceStr)
 	if err != nil {
 		return fmt.Errorf("invalid since duration: %w", err)
@@ -46,27 +64,290 @@ func runSync(cmd *cobra.Command, args []string) error {
 	sinceTime := time.Now().Add(-since)
 
 	if verbose {
-		fmt.Printf("📅 Syncing commits since: %s\n", sinceTime.Format(time.RFC3339))
+		if incremental {
+			fmt.Printf("📅 Syncing commits since last run (new repositories since: %s)\n", sinceTime.Format(time.RFC3339))
+		} else {
+			fmt.Printf("📅 Syncing commits since: %s\n", sinceTime.Format(time.RFC3339))
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
 	}
 
+	sourceNames, _ := cmd.Flags().GetStringSlice("sources")
+	targetNames, _ := cmd.Flags().GetStringSlice("targets")
+	force, _ := cmd.Flags().GetBool("force")
+
 	if dryRun {
 		fmt.Println("🧪 Dry run mode - no changes will be made")
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	result, err := syncOnce(ctx, config, sourceNames, targetNames, sinceTime, mirror.Options{
+		SkipExisting: !force,
+		DryRun:       dryRun,
+		Verbose:      verbose,
+		Incremental:  incremental,
+	})
+	if err != nil {
+		for _, hint := range hintsFor(err) {
+			fmt.Println(hint)
+		}
+		return fmt.Errorf("sync finished with errors: %w", err)
+	}
+
+	if dryRun {
 		fmt.Println("✅ Sync completed (dry run)")
 		return nil
 	}
 
-	// TODO: Implement actual sync logic here
-	// This would:
-	// 1. Load configuration
-	// 2. Initialize source and target platforms
-	// 3. Fetch commits from sources since the specified time
-	// 4. Mirror commits to targets
+	fmt.Printf("✅ Sync completed successfully (%d commits mirrored)\n", result.Mirrored)
+
+	return nil
+}
+
+// syncOnce runs a single sync with the ledger and cursors from the state
+// directory, holding the ledger lock for the duration of the run
+func syncOnce(ctx context.Context, config *Config, sourceNames, targetNames []string, since time.Time, opts mirror.Options) (mirror.Result, error) {
+	ledger, err := openLedger()
+	if err != nil {
+		return mirror.Result{}, err
+	}
+	defer ledger.Close()
+
+	cursors, err := loadCursors()
+	if err != nil {
+		return mirror.Result{}, err
+	}
+
+	opts.Ledger = ledger
+	opts.Cursors = cursors
+
+	engine, err := buildEngine(config, sourceNames, targetNames, opts)
+	if err != nil {
+		return mirror.Result{}, err
+	}
+
+	return engine.Sync(ctx, since)
+}
+
+// buildEngine creates the configured source and target platforms, limited to
+// the given names when any are passed, and wires them into a sync engine
+func buildEngine(config *Config, sourceNames, targetNames []string, opts mirror.Options) (*mirror.Engine, error) {
+	var configuredSources, configuredTargets []string
+	for _, sc := range config.Sources {
+		configuredSources = append(configuredSources, sc.Name)
+	}
+	for _, tc := range config.Targets {
+		configuredTargets = append(configuredTargets, tc.Name)
+	}
+	if err := checkNames("source", sourceNames, configuredSources); err != nil {
+		return nil, err
+	}
+	if err := checkNames("target", targetNames, configuredTargets); err != nil {
+		return nil, err
+	}
+
+	rateLimitWait, err := rateLimitWait(config)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := requestTimeout(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []mirror.Source
+	for _, sc := range config.Sources {
+		if !selected(sc.Name, sourceNames) {
+			continue
+		}
+
+		pc := sc.platformConfig()
+		pc.Identities = config.Identities.platformIdentities()
+		pc.RateLimitWait = rateLimitWait
+		pc.Timeout = timeout
+		if pc.Mailmap, err = platforms.LoadMailmap(sc.Mailmap...); err != nil {
+			return nil, fmt.Errorf("source %s: %w", sc.Name, err)
+		}
+		platform, err := platforms.NewPlatform(platforms.PlatformType(sc.Platform), pc)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", sc.Name, err)
+		}
+
+		message, err := parseTemplate(sc.CommitMessage)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", sc.Name, err)
+		}
+
+		// Generic git sources expand their paths and scan directories themselves
+		repositories := sc.Repositories
+		if platform.GetPlatformType() == platforms.PlatformGenericGit {
+			repositories = nil
+		}
 
-	fmt.Println("✅ Sync completed successfully")
+		sources = append(sources, mirror.Source{
+			Name:          sc.Name,
+			Platform:      platform,
+			Repositories:  repositories,
+			Aliases:       sc.Aliases,
+			CommitMessage: message,
+		})
+	}
+
+	var targets []mirror.Target
+	for _, tc := range config.Targets {
+		if !selected(tc.Name, targetNames) {
+			continue
+		}
+
+		pc := tc.platformConfig()
+		pc.RateLimitWait = rateLimitWait
+		pc.Timeout = timeout
+		platform, err := platforms.NewPlatform(pc.Platform, pc)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", tc.Name, err)
+		}
+
+		message, err := parseTemplate(tc.CommitMessage)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", tc.Name, err)
+		}
+
+		targets = append(targets, mirror.Target{
+			Name:          tc.Name,
+			Platform:      platform,
+			Config:        pc,
+			CommitMessage: message,
+		})
+	}
+
+	message, err := parseTemplate(config.Sync.CommitMessage)
+	if err != nil {
+		return nil, fmt.Errorf("sync: %w", err)
+	}
+	opts.CommitMessage = message
+
+	loc, err := loadLocation(config.Sync.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("sync: %w", err)
+	}
+	opts.Location = loc
+
+	if config.Sync.TrailerKey != "" {
+		opts.TrailerKey = []byte(config.Sync.TrailerKey)
+	}
+	// Without a configured key, repository aliases are keyed with one kept
+	// in the state directory; hashed mirrors require a configured key
+	if config.Sync.NameKey != "" {
+		opts.NameKey = []byte(config.Sync.NameKey)
+	} else if opts.NameKey, err = loadNameKey(); err != nil {
+		return nil, err
+	}
+
+	return mirror.NewEngine(sources, targets, opts), nil
+}
+
+// requestTimeout returns the limit on each API request and git command:
+// --timeout, then sync.timeout, then defaultRequestTimeout. "0" disables the
+// limit.
+func requestTimeout(config *Config) (time.Duration, error) {
+	value := viper.GetString("timeout")
+	if value == "" {
+		value = config.Sync.Timeout
+	}
+	if value == "" {
+		value = defaultRequestTimeout
+	}
+	if value == "0" {
+		return 0, nil
+	}
+
+	timeout, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	return timeout, nil
+}
+
+// rateLimitWait returns how long each platform may wait for rate limits to
+// lift during a run, from sync.rate_limit_wait
+func rateLimitWait(config *Config) (time.Duration, error) {
+	if config.Sync.RateLimitWait == "" {
+		return platforms.DefaultRateLimitWait, nil
+	}
+
+	wait, err := parseDuration(config.Sync.RateLimitWait)
+	if err != nil || wait <= 0 {
+		return 0, fmt.Errorf("invalid sync.rate_limit_wait %q: must be a positive duration", config.Sync.RateLimitWait)
+	}
+	return wait, nil
+}
+
+// openLedger opens the ledger of mirrored commits in the state directory
+func openLedger() (*mirror.Ledger, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return mirror.OpenLedger(filepath.Join(dir, "ledger.json"))
+}
+
+// loadCursors loads the per-repository sync cursors from the state directory
+func loadCursors() (*mirror.Cursors, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return mirror.LoadCursors(filepath.Join(dir, "cursors.json"))
+}
+
+// loadNameKey loads the generated name key from the state directory
+func loadNameKey() ([]byte, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return mirror.LoadNameKey(filepath.Join(dir, "name.key"))
+}
+
+// stateDir returns the directory holding configuration and sync state
+func stateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".git-activity-mirror"), nil
+}
+
+// checkNames makes sure every name passed on the command line is configured
+func checkNames(kind string, names, configured []string) error {
+	for _, n := range names {
+		if !selected(n, configured) {
+			return fmt.Errorf("unknown %s: %s", kind, n)
+		}
+	}
 
 	return nil
 }
 
+// selected reports whether name passes an optional --sources/--targets filter
+func selected(name string, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
 // parseDuration parses duration strings like "24h", "7d", "1w", "3mo", "1y"
 func parseDuration(s string) (time.Duration, error) {
 	// Handle common suffixes
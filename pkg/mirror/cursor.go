@@ -0,0 +1,130 @@
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+// Cursor is the high-water mark of a source repository on a target: the
+// newest commit the target received in the last run that reached it
+type Cursor struct {
+	Source    string    `json:"source"`
+	Repo      string    `json:"repo"`
+	Target    string    `json:"target,omitempty"` // empty in cursors saved before they were kept per target
+	SHA       string    `json:"sha"`
+	Date      time.Time `json:"date"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Cursors is the on-disk set of per-repository cursors. It lives next to the
+// ledger and is only written while the ledger lock is held.
+type Cursors struct {
+	path    string
+	cursors map[string]Cursor
+}
+
+// LoadCursors reads the cursors at path; a missing file means no cursors yet
+func LoadCursors(path string) (*Cursors, error) {
+	c := &Cursors{
+		path:    path,
+		cursors: make(map[string]Cursor),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cursors: %w", err)
+	}
+
+	var list []Cursor
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse cursors %s: %w", path, err)
+	}
+	for _, cursor := range list {
+		c.cursors[cursorKey(cursor.Source, cursor.Repo, cursor.Target)] = cursor
+	}
+
+	return c, nil
+}
+
+// Get returns the cursor of a source repository on a target, if one was
+// saved. Targets without one fall back to a cursor saved before cursors were
+// kept per target.
+func (c *Cursors) Get(source, repo, target string) (Cursor, bool) {
+	if cursor, ok := c.cursors[cursorKey(source, repo, target)]; ok {
+		return cursor, true
+	}
+	cursor, ok := c.cursors[cursorKey(source, repo, "")]
+	return cursor, ok
+}
+
+// Advance moves cursors forward to the given ones and saves them. Cursors
+// never move backwards, so replaying an older window is harmless.
+func (c *Cursors) Advance(updates []Cursor) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, update := range updates {
+		key := cursorKey(update.Source, update.Repo, update.Target)
+		if current, ok := c.cursors[key]; ok && !update.Date.After(current.Date) {
+			continue
+		}
+		update.UpdatedAt = now
+		c.cursors[key] = update
+	}
+
+	list := make([]Cursor, 0, len(c.cursors))
+	for _, cursor := range c.cursors {
+		list = append(list, cursor)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return cursorKey(list[i].Source, list[i].Repo, list[i].Target) < cursorKey(list[j].Source, list[j].Repo, list[j].Target)
+	})
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cursor directory: %w", err)
+	}
+	if err := writeJSONAtomic(c.path, list); err != nil {
+		return fmt.Errorf("failed to save cursors: %w", err)
+	}
+
+	return nil
+}
+
+// newestCursor returns a cursor pointing at the most recently committed of the
+// given commits. Committer dates are used because rebased, cherry-picked or
+// late-pushed commits keep their older author dates.
+func newestCursor(source string, repo platforms.Repository, commits []platforms.Commit) (Cursor, bool) {
+	if len(commits) == 0 {
+		return Cursor{}, false
+	}
+
+	newest := commits[0]
+	for _, commit := range commits[1:] {
+		if commit.CommitDate().After(newest.CommitDate()) {
+			newest = commit
+		}
+	}
+
+	return Cursor{
+		Source: source,
+		Repo:   repo.FullName,
+		SHA:    newest.SHA,
+		Date:   newest.CommitDate(),
+	}, true
+}
+
+func cursorKey(source, repo, target string) string {
+	return source + "\x00" + repo + "\x00" + target
+}
@@ -0,0 +1,638 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+// Source is a configured platform that commits are read from
+type Source struct {
+	Name          string
+	Platform      platforms.GitPlatform
+	Repositories  []string
+	Aliases       map[string]string // repository name to {repo} alias
+	CommitMessage *MessageTemplate  // overrides Options.CommitMessage (optional)
+}
+
+// Target is a configured platform that mirror commits are written to. Platform
+// writes to Config.Mirror.Repository; strategies that need more repositories
+// get further instances created from Config.
+type Target struct {
+	Name          string
+	Platform      platforms.GitPlatform
+	Config        platforms.PlatformConfig
+	CommitMessage *MessageTemplate // overrides source and global templates (optional)
+}
+
+// Options controls how the engine runs
+type Options struct {
+	SkipExisting  bool             // skip commits already present in the target
+	BatchSize     int              // commits written per MirrorCommits call (0: all at once)
+	DryRun        bool             // fetch commits but don't write anything
+	Verbose       bool             // print per-repository progress
+	Out           io.Writer        // progress output (default: stdout)
+	Ledger        *Ledger          // record of mirrored commits (optional)
+	Cursors       *Cursors         // per-repository, per-target high-water marks (optional)
+	Incremental   bool             // fetch from each repository's oldest target cursor instead of the given time
+	TrailerKey    []byte           // HMAC key for fingerprint trailers on mirror commits (optional)
+	NameKey       []byte           // secret HMAC key for hashed mirror and repository names (must never change)
+	CommitMessage *MessageTemplate // mirror commit message (default: DefaultCommitMessage)
+	Location      *time.Location   // zone mirror timestamps and days are expressed in (default: local)
+}
+
+// Result summarizes a sync run
+type Result struct {
+	Fetched  int // commits read from sources
+	Excluded int // commits left out as made by someone outside the configured identities
+	Skipped  int // commits dropped as duplicates or already mirrored, summed over all targets
+	Mirrored int // commits written, summed over all targets
+}
+
+// Engine reads commits from sources and mirrors them to targets
+type Engine struct {
+	sources   []Source
+	targets   []Target
+	opts      Options
+	out       io.Writer
+	instances map[string]platforms.GitPlatform // per-repository target platforms
+	byName    map[string]Source
+}
+
+// NewEngine creates a new sync engine
+func NewEngine(sources []Source, targets []Target, opts Options) *Engine {
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.CommitMessage == nil {
+		opts.CommitMessage, _ = ParseMessageTemplate(DefaultCommitMessage)
+	}
+
+	byName := make(map[string]Source, len(sources))
+	for _, source := range sources {
+		byName[source.Name] = source
+	}
+
+	return &Engine{
+		sources:   sources,
+		targets:   targets,
+		opts:      opts,
+		out:       out,
+		instances: make(map[string]platforms.GitPlatform),
+		byName:    byName,
+	}
+}
+
+// Sync fetches commits made since the given time from every source and
+// mirrors them to every target. In incremental mode, repositories with saved
+// cursors on every target are read from the oldest of them instead. Failures on a single
+// repository or target are reported and the run carries on; they are
+// returned joined at the end. Cancelling ctx stops the run after the
+// calls in flight are aborted.
+func (e *Engine) Sync(ctx context.Context, since time.Time) (Result, error) {
+	return e.run(ctx, since)
+}
+
+// Import mirrors the full history since the given time, writing it to each
+// target in chronological batches of Options.BatchSize commits
+func (e *Engine) Import(ctx context.Context, since time.Time) (Result, error) {
+	return e.run(ctx, since)
+}
+
+func (e *Engine) run(ctx context.Context, since time.Time) (Result, error) {
+	var result Result
+
+	excluded := e.excluded()
+	commits, cursors, errs := e.fetch(ctx, since)
+	result.Fetched = len(commits)
+	result.Excluded = e.excluded() - excluded
+	if ctx.Err() != nil {
+		return result, errors.Join(errs...)
+	}
+
+	commits, duplicates := dedupe(commits)
+	result.Skipped = duplicates
+	sortCommits(commits)
+
+	fmt.Fprintf(e.out, "📥 Fetched %d commits from %d sources\n", result.Fetched, len(e.sources))
+	if result.Excluded > 0 {
+		fmt.Fprintf(e.out, "🙈 Excluded %d commits by other authors\n", result.Excluded)
+	}
+	if duplicates > 0 {
+		fmt.Fprintf(e.out, "⏭️  Skipped %d duplicate commits\n", duplicates)
+	}
+
+	mirrored, skipped, failed, targetErrs := e.mirrorAll(ctx, commits)
+	result.Mirrored += mirrored
+	result.Skipped += skipped
+	errs = append(errs, targetErrs...)
+
+	// Only move a target's cursors once it has the commits, otherwise the
+	// next incremental run would never pick up what it missed. Targets left
+	// out of this run keep theirs.
+	if e.opts.Cursors != nil && !e.opts.DryRun && ctx.Err() == nil {
+		var updates []Cursor
+		for _, target := range e.targets {
+			if failed[target.Name] {
+				continue
+			}
+			for _, cursor := range cursors {
+				cursor.Target = target.Name
+				updates = append(updates, cursor)
+			}
+		}
+		if err := e.opts.Cursors.Advance(updates); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// MirrorPushed mirrors commits that were pushed to a source repository, as
+// reported by a webhook, to every target. They go through the same identity
+// filter, normalization, deduplication and skip checks as fetched commits.
+func (e *Engine) MirrorPushed(ctx context.Context, source string, commits []platforms.Commit) (Result, error) {
+	var result Result
+
+	if src, ok := e.byName[source]; ok {
+		own, err := src.Platform.OwnCommits(ctx, commits)
+		if err != nil {
+			return result, fmt.Errorf("source %s: %w", source, err)
+		}
+		result.Excluded = len(commits) - len(own)
+		commits = own
+	}
+	result.Fetched = len(commits)
+
+	e.prepare(source, commits)
+	commits, duplicates := dedupe(commits)
+	result.Skipped = duplicates
+	sortCommits(commits)
+
+	mirrored, skipped, _, errs := e.mirrorAll(ctx, commits)
+	result.Mirrored += mirrored
+	result.Skipped += skipped
+
+	return result, errors.Join(errs...)
+}
+
+// mirrorAll writes commits to every target, reporting which targets failed
+func (e *Engine) mirrorAll(ctx context.Context, commits []platforms.Commit) (mirrored, skipped int, failed map[string]bool, errs []error) {
+	failed = make(map[string]bool)
+
+	for _, target := range e.targets {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		m, s, err := e.mirror(ctx, target, commits)
+		mirrored += m
+		skipped += s
+		if err != nil {
+			fmt.Fprintf(e.out, "❌ %s: %v\n", target.Name, err)
+			errs = append(errs, fmt.Errorf("target %s: %w", target.Name, err))
+			failed[target.Name] = true
+		}
+	}
+
+	return mirrored, skipped, failed, errs
+}
+
+// fetch collects commits from all repositories of all sources, along with
+// the cursor each successfully read repository should advance to
+func (e *Engine) fetch(ctx context.Context, since time.Time) ([]platforms.Commit, []Cursor, []error) {
+	var all []platforms.Commit
+	var cursors []Cursor
+	var errs []error
+
+	for _, source := range e.sources {
+		repos, err := resolveRepositories(ctx, source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", source.Name, err))
+			continue
+		}
+
+		for _, repo := range repos {
+			if err := ctx.Err(); err != nil {
+				return all, cursors, append(errs, err)
+			}
+
+			repoSince := since
+			var last Cursor
+			if e.opts.Incremental && e.opts.Cursors != nil {
+				if cursor, ok := e.oldestCursor(source.Name, repo.FullName); ok {
+					repoSince, last = cursor.Date, cursor
+				}
+			}
+
+			excluded := source.Platform.ExcludedCommits()
+			commits, err := source.Platform.GetCommits(ctx, repo, repoSince)
+			if err != nil {
+				fmt.Fprintf(e.out, "❌ %s/%s: %v\n", source.Name, repo.FullName, err)
+				errs = append(errs, fmt.Errorf("source %s, repository %s: %w", source.Name, repo.FullName, err))
+				continue
+			}
+
+			// The cursor commit itself comes back since "since" is inclusive;
+			// every target already has it
+			if last.SHA != "" {
+				commits = withoutSHA(commits, last.SHA)
+			}
+
+			if e.opts.Verbose {
+				fmt.Fprintf(e.out, "  📂 %s/%s: %d commits since %s", source.Name, repo.FullName, len(commits), repoSince.Format(time.RFC3339))
+				if n := source.Platform.ExcludedCommits() - excluded; n > 0 {
+					fmt.Fprintf(e.out, " (%d by others excluded)", n)
+				}
+				fmt.Fprintln(e.out)
+			}
+			e.prepare(source.Name, commits)
+			if cursor, ok := newestCursor(source.Name, repo, commits); ok {
+				cursors = append(cursors, cursor)
+			}
+			all = append(all, commits...)
+		}
+	}
+
+	return all, cursors, errs
+}
+
+// oldestCursor returns the cursor of a source repository that lags furthest
+// behind across the targets, or false if any target has none yet
+func (e *Engine) oldestCursor(source, repo string) (Cursor, bool) {
+	var oldest Cursor
+	for i, target := range e.targets {
+		cursor, ok := e.opts.Cursors.Get(source, repo, target.Name)
+		if !ok {
+			return Cursor{}, false
+		}
+		if i == 0 || cursor.Date.Before(oldest.Date) {
+			oldest = cursor
+		}
+	}
+	return oldest, len(e.targets) > 0
+}
+
+// excluded returns how many commits the sources have left out so far
+func (e *Engine) excluded() int {
+	var n int
+	for _, source := range e.sources {
+		n += source.Platform.ExcludedCommits()
+	}
+	return n
+}
+
+// prepare tags commits with their source and normalizes them into the
+// configured zone so each commit lands on the day it was made there, both in
+// messages and on the mirror
+func (e *Engine) prepare(source string, commits []platforms.Commit) {
+	for i := range commits {
+		commits[i].Source = source
+		commits[i].Date = commits[i].Date.In(e.opts.Location)
+	}
+}
+
+// mirror writes commits to a single target, routing each source's commits to
+// the mirror repository the target's strategy assigns it. It returns how many
+// commits were written and how many were skipped as already present.
+func (e *Engine) mirror(ctx context.Context, target Target, commits []platforms.Commit) (int, int, error) {
+	var mirrored, skipped int
+	var errs []error
+
+	for _, repository := range e.mirrorRepositories(target) {
+		var routed []platforms.Commit
+		for _, commit := range commits {
+			if e.mirrorRepository(target, commit.Source) == repository {
+				routed = append(routed, commit)
+			}
+		}
+
+		platform, err := e.targetPlatform(target, repository)
+		if err == nil {
+			var m, s int
+			m, s, err = e.mirrorTo(ctx, target, platform, repository, routed)
+			mirrored += m
+			skipped += s
+		}
+		if err != nil {
+			if repository != target.Config.Mirror.Repository {
+				err = fmt.Errorf("%s: %w", repository, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	return mirrored, skipped, errors.Join(errs...)
+}
+
+// mirrorTo writes commits to one mirror repository of a target in batches
+func (e *Engine) mirrorTo(ctx context.Context, target Target, platform platforms.GitPlatform, repository string, commits []platforms.Commit) (int, int, error) {
+	if !e.opts.DryRun {
+		if err := platform.InitializeMirror(ctx, repository, target.Config.Mirror.Visibility); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var skipped int
+	if e.opts.SkipExisting && len(commits) > 0 {
+		// Commits are sorted, so the first one bounds the mirror history to scan
+		existing, err := platform.ListMirrorCommits(ctx, commits[0].Date)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if e.opts.TrailerKey != nil {
+			candidates := commits
+			var matched []platforms.MirroredCommit
+			commits, existing, matched = e.skipFingerprinted(commits, existing)
+			skipped += len(matched)
+
+			// Rebuild the ledger from the mirror itself, e.g. on a new machine
+			if e.opts.Ledger != nil && !e.opts.DryRun {
+				if err := e.opts.Ledger.Record(target.Name, repository, candidates, matched); err != nil {
+					return 0, skipped, err
+				}
+			}
+		}
+
+		var present int
+		commits, present = skipMirrored(commits, existing)
+		skipped += present
+
+		// The ledger also catches mirror commits whose dates don't match the
+		// source, e.g. on targets that can't preserve timestamps
+		if e.opts.Ledger != nil {
+			var remaining []platforms.Commit
+			for _, commit := range commits {
+				if !e.opts.Ledger.Has(target.Name, commit) {
+					remaining = append(remaining, commit)
+				}
+			}
+			skipped += len(commits) - len(remaining)
+			commits = remaining
+		}
+	}
+	if skipped > 0 {
+		fmt.Fprintf(e.out, "⏭️  %s: %d commits already mirrored\n", target.Name, skipped)
+	}
+
+	if len(commits) == 0 {
+		if e.opts.Verbose {
+			fmt.Fprintf(e.out, "🎯 %s: nothing to mirror\n", target.Name)
+		}
+		return 0, skipped, nil
+	}
+
+	if e.opts.DryRun {
+		fmt.Fprintf(e.out, "🧪 %s: would mirror %d commits to %s (%s to %s)\n", target.Name, len(commits),
+			repository, commits[0].Date.Format("2006-01-02"), commits[len(commits)-1].Date.Format("2006-01-02"))
+		return 0, skipped, nil
+	}
+
+	perDay := make(map[string]int)
+	if e.opts.Ledger != nil {
+		perDay = e.opts.Ledger.DayCounts(target.Name, repository, e.opts.Location)
+	}
+
+	batches := batch(commits, e.opts.BatchSize)
+	var mirrored int
+	for i, b := range batches {
+		created, err := platform.MirrorCommits(ctx, e.mirrorCommits(target, b, perDay))
+		mirrored += len(created)
+
+		// Record whatever was created, even if the batch failed part way
+		if e.opts.Ledger != nil {
+			if lerr := e.opts.Ledger.Record(target.Name, repository, b, created); lerr != nil {
+				return mirrored, skipped, lerr
+			}
+		}
+		if err != nil {
+			return mirrored, skipped, fmt.Errorf("batch %d/%d: %w", i+1, len(batches), err)
+		}
+
+		if len(batches) > 1 {
+			fmt.Fprintf(e.out, "  📦 %s: batch %d/%d (%d/%d commits, up to %s)\n", target.Name, i+1, len(batches),
+				mirrored, len(commits), b[len(b)-1].Date.Format("2006-01-02"))
+		}
+	}
+
+	fmt.Fprintf(e.out, "🎯 %s: mirrored %d commits to %s\n", target.Name, mirrored, repository)
+	return mirrored, skipped, nil
+}
+
+// mirrorCommits replaces everything that could identify a source commit with
+// the rendered mirror message, keeping only its SHA (never written to the
+// mirror) to link the result back, and its timestamp. perDay counts the
+// commits already on the target for each day and is advanced as messages
+// are rendered.
+func (e *Engine) mirrorCommits(target Target, commits []platforms.Commit, perDay map[string]int) []platforms.Commit {
+	out := make([]platforms.Commit, len(commits))
+	for i, commit := range commits {
+		source := e.byName[commit.Source]
+
+		day := commit.Date.Format("2006-01-02")
+		perDay[day]++
+
+		message := e.messageTemplate(target, source).Render(MessageData{
+			Date:     commit.Date,
+			Source:   commit.Source,
+			Platform: commit.Platform,
+			Repo:     e.repoAlias(source, commit.Repo),
+			Index:    perDay[day],
+		})
+		if e.opts.TrailerKey != nil {
+			message = withTrailer(message, Fingerprint(e.opts.TrailerKey, commit.SHA))
+		}
+
+		out[i] = platforms.Commit{
+			SHA:      commit.SHA,
+			Source:   commit.Source,
+			Message:  message,
+			Date:     commit.Date,
+			Platform: commit.Platform,
+		}
+	}
+	return out
+}
+
+// messageTemplate picks the most specific template: target, then source,
+// then the global one
+func (e *Engine) messageTemplate(target Target, source Source) *MessageTemplate {
+	switch {
+	case target.CommitMessage != nil:
+		return target.CommitMessage
+	case source.CommitMessage != nil:
+		return source.CommitMessage
+	default:
+		return e.opts.CommitMessage
+	}
+}
+
+// repoAlias returns the configured alias of a repository, or an obfuscated
+// name so the real one never reaches the mirror
+func (e *Engine) repoAlias(source Source, repo string) string {
+	if alias, ok := source.Aliases[repo]; ok {
+		return alias
+	}
+	if alias, ok := source.Aliases[path.Base(repo)]; ok {
+		return alias
+	}
+	return "repo-" + e.hashName(source.Name+"/"+repo)
+}
+
+// skipFingerprinted drops commits whose fingerprint trailer is already on the
+// mirror. It returns the remaining commits, the mirror commits that carry no
+// fingerprint (left for timestamp matching) and the matches found.
+func (e *Engine) skipFingerprinted(commits, existing []platforms.Commit) ([]platforms.Commit, []platforms.Commit, []platforms.MirroredCommit) {
+	onMirror := make(map[string]string, len(existing))
+	var unmarked []platforms.Commit
+	for _, commit := range existing {
+		if fingerprint, ok := parseTrailer(commit.Message); ok {
+			onMirror[fingerprint] = commit.SHA
+			continue
+		}
+		unmarked = append(unmarked, commit)
+	}
+
+	var remaining []platforms.Commit
+	var matched []platforms.MirroredCommit
+	for _, commit := range commits {
+		if mirrorSHA, ok := onMirror[Fingerprint(e.opts.TrailerKey, commit.SHA)]; ok {
+			matched = append(matched, platforms.MirroredCommit{SourceSHA: commit.SHA, MirrorSHA: mirrorSHA})
+			continue
+		}
+		remaining = append(remaining, commit)
+	}
+
+	return remaining, unmarked, matched
+}
+
+// resolveRepositories maps configured repository names onto the repositories
+// the platform reports. Names the listing doesn't know about (e.g. repositories
+// owned by an organization) are passed through as-is. With no repositories
+// configured, every repository on the platform is used.
+func resolveRepositories(ctx context.Context, source Source) ([]platforms.Repository, error) {
+	listed, err := source.Platform.ListRepositories(ctx)
+	if err != nil {
+		if len(source.Repositories) == 0 {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		listed = nil
+	}
+
+	if len(source.Repositories) == 0 {
+		return listed, nil
+	}
+
+	byName := make(map[string]platforms.Repository, len(listed)*2)
+	for _, repo := range listed {
+		byName[repo.FullName] = repo
+		if _, ok := byName[repo.Name]; !ok {
+			byName[repo.Name] = repo
+		}
+	}
+
+	repos := make([]platforms.Repository, 0, len(source.Repositories))
+	for _, name := range source.Repositories {
+		if repo, ok := byName[name]; ok {
+			repos = append(repos, repo)
+			continue
+		}
+		repos = append(repos, platforms.Repository{
+			ID:       name,
+			Name:     path.Base(name),
+			FullName: name,
+			Platform: string(source.Platform.GetPlatformType()),
+		})
+	}
+
+	return repos, nil
+}
+
+// dedupe drops commits whose SHA was already seen, e.g. when the same history
+// is reachable from a fork and its upstream
+func dedupe(commits []platforms.Commit) ([]platforms.Commit, int) {
+	seen := make(map[string]bool, len(commits))
+	unique := commits[:0]
+
+	for _, commit := range commits {
+		if seen[commit.SHA] {
+			continue
+		}
+		seen[commit.SHA] = true
+		unique = append(unique, commit)
+	}
+
+	return unique, len(commits) - len(unique)
+}
+
+// withoutSHA drops the commit with the given SHA
+func withoutSHA(commits []platforms.Commit, sha string) []platforms.Commit {
+	kept := commits[:0]
+	for _, commit := range commits {
+		if commit.SHA != sha {
+			kept = append(kept, commit)
+		}
+	}
+	return kept
+}
+
+// sortCommits orders commits oldest first so mirrors build history forward
+func sortCommits(commits []platforms.Commit) {
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Date.Before(commits[j].Date)
+	})
+}
+
+// skipMirrored drops commits whose timestamp already appears on the mirror.
+// Mirror commits carry the source commit's author date, so each existing
+// mirror commit accounts for one source commit made at the same second.
+func skipMirrored(commits, existing []platforms.Commit) ([]platforms.Commit, int) {
+	present := make(map[int64]int, len(existing))
+	for _, commit := range existing {
+		present[commit.Date.Unix()]++
+	}
+
+	var remaining []platforms.Commit
+	for _, commit := range commits {
+		if present[commit.Date.Unix()] > 0 {
+			present[commit.Date.Unix()]--
+			continue
+		}
+		remaining = append(remaining, commit)
+	}
+
+	return remaining, len(commits) - len(remaining)
+}
+
+// batch splits commits into consecutive chunks of at most size commits
+func batch(commits []platforms.Commit, size int) [][]platforms.Commit {
+	if size <= 0 || size >= len(commits) {
+		return [][]platforms.Commit{commits}
+	}
+
+	var batches [][]platforms.Commit
+	for start := 0; start < len(commits); start += size {
+		end := start + size
+		if end > len(commits) {
+			end = len(commits)
+		}
+		batches = append(batches, commits[start:end])
+	}
+
+	return batches
+}
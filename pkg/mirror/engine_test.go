@@ -0,0 +1,250 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+// fakePlatform serves a fixed set of source commits and records what is
+// mirrored to it
+type fakePlatform struct {
+	commits  []platforms.Commit
+	mirrored []platforms.Commit
+	fail     error
+}
+
+func (f *fakePlatform) Connect(ctx context.Context, config platforms.AuthConfig) error { return nil }
+func (f *fakePlatform) ValidateCredentials(ctx context.Context) error                  { return nil }
+func (f *fakePlatform) Disconnect(ctx context.Context) error                           { return nil }
+
+func (f *fakePlatform) ListRepositories(ctx context.Context) ([]platforms.Repository, error) {
+	return []platforms.Repository{{FullName: "me/repo"}}, nil
+}
+
+// GetCommits returns the commits committed on or after since
+func (f *fakePlatform) GetCommits(ctx context.Context, repo platforms.Repository, since time.Time) ([]platforms.Commit, error) {
+	var commits []platforms.Commit
+	for _, commit := range f.commits {
+		if !commit.CommitDate().Before(since) {
+			commits = append(commits, commit)
+		}
+	}
+	return commits, nil
+}
+
+func (f *fakePlatform) GetCommitCount(ctx context.Context, repo platforms.Repository, since time.Time) (int, error) {
+	commits, err := f.GetCommits(ctx, repo, since)
+	return len(commits), err
+}
+
+func (f *fakePlatform) OwnCommits(ctx context.Context, commits []platforms.Commit) ([]platforms.Commit, error) {
+	return commits, nil
+}
+
+func (f *fakePlatform) ExcludedCommits() int { return 0 }
+
+func (f *fakePlatform) InitializeMirror(ctx context.Context, name string, visibility string) error {
+	return nil
+}
+
+func (f *fakePlatform) MirrorCommits(ctx context.Context, commits []platforms.Commit) ([]platforms.MirroredCommit, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+
+	var created []platforms.MirroredCommit
+	for _, commit := range commits {
+		f.mirrored = append(f.mirrored, commit)
+		created = append(created, platforms.MirroredCommit{SourceSHA: commit.SHA, MirrorSHA: "m-" + commit.SHA})
+	}
+	return created, nil
+}
+
+func (f *fakePlatform) ListMirrorCommits(ctx context.Context, since time.Time) ([]platforms.Commit, error) {
+	return nil, nil
+}
+
+func (f *fakePlatform) GetMirrorStatus(ctx context.Context) (platforms.MirrorStatus, error) {
+	return platforms.MirrorStatus{}, nil
+}
+
+func (f *fakePlatform) GetPlatformName() string                 { return "Fake" }
+func (f *fakePlatform) GetPlatformType() platforms.PlatformType { return "fake" }
+func (f *fakePlatform) SupportsWebhooks() bool                  { return false }
+
+// newTestEngine wires a source into an engine writing to the given targets,
+// keeping cursors in dir
+func newTestEngine(t *testing.T, dir string, source *fakePlatform, targets map[string]*fakePlatform) *Engine {
+	t.Helper()
+
+	cursors, err := LoadCursors(filepath.Join(dir, "cursors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list []Target
+	for _, name := range []string{"a", "b"} {
+		if platform, ok := targets[name]; ok {
+			list = append(list, Target{
+				Name:     name,
+				Platform: platform,
+				Config:   platforms.PlatformConfig{Mirror: platforms.MirrorConfig{Repository: "mirror"}},
+			})
+		}
+	}
+
+	return NewEngine(
+		[]Source{{Name: "src", Platform: source}},
+		list,
+		Options{Cursors: cursors, Incremental: true, Out: io.Discard, Location: time.UTC},
+	)
+}
+
+func TestCursorsAdvancePerTarget(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	source := &fakePlatform{commits: []platforms.Commit{
+		{SHA: "1", Date: start.Add(time.Hour)},
+		{SHA: "2", Date: start.Add(2 * time.Hour)},
+	}}
+	a, b := &fakePlatform{}, &fakePlatform{}
+
+	// A run limited to target a leaves b's cursor alone
+	if _, err := newTestEngine(t, dir, source, map[string]*fakePlatform{"a": a}).Sync(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+
+	source.commits = append(source.commits, platforms.Commit{SHA: "3", Date: start.Add(3 * time.Hour)})
+	if _, err := newTestEngine(t, dir, source, map[string]*fakePlatform{"a": a, "b": b}).Sync(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	if got := shas(b.mirrored); got != "1,2,3" {
+		t.Errorf("target b got %s, want 1,2,3", got)
+	}
+
+	// A failing target keeps its cursor while the other one moves on
+	source.commits = append(source.commits, platforms.Commit{SHA: "4", Date: start.Add(4 * time.Hour)})
+	b.fail = errors.New("unreachable")
+	if _, err := newTestEngine(t, dir, source, map[string]*fakePlatform{"a": a, "b": b}).Sync(ctx, start); err == nil {
+		t.Fatal("expected the failing target's error")
+	}
+
+	b.fail = nil
+	b.mirrored = nil
+	if _, err := newTestEngine(t, dir, source, map[string]*fakePlatform{"b": b}).Sync(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	if got := shas(b.mirrored); got != "4" {
+		t.Errorf("target b got %s after recovering, want 4", got)
+	}
+}
+
+func TestCursorsFollowCommitterDates(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	source := &fakePlatform{commits: []platforms.Commit{
+		{SHA: "1", Date: start.Add(2 * time.Hour)},
+	}}
+	a := &fakePlatform{}
+	if _, err := newTestEngine(t, dir, source, map[string]*fakePlatform{"a": a}).Sync(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rebased commit keeps its older author date but is committed later
+	source.commits = append(source.commits, platforms.Commit{
+		SHA:       "2",
+		Date:      start.Add(time.Hour),
+		Committed: start.Add(3 * time.Hour),
+	})
+	if _, err := newTestEngine(t, dir, source, map[string]*fakePlatform{"a": a}).Sync(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	if got := shas(a.mirrored); got != "1,2" {
+		t.Errorf("target a got %s, want 1,2", got)
+	}
+}
+
+// shas lists the SHAs of commits in order
+func shas(commits []platforms.Commit) string {
+	var list string
+	for i, commit := range commits {
+		if i > 0 {
+			list += ","
+		}
+		list += commit.SHA
+	}
+	return list
+}
+
+func TestDayIndexPerMirrorRepository(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	ledger, err := OpenLedger(filepath.Join(dir, "ledger.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ledger.Close()
+
+	message, err := ParseMessageTemplate("{n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work := &fakePlatform{commits: []platforms.Commit{{SHA: "w1", Date: day}}}
+	home := &fakePlatform{commits: []platforms.Commit{{SHA: "h1", Date: day.Add(time.Hour)}}}
+	mirrors := map[string]*fakePlatform{"mirror-work": {}, "mirror-home": {}}
+
+	sync := func() {
+		t.Helper()
+
+		engine := NewEngine(
+			[]Source{{Name: "work", Platform: work}, {Name: "home", Platform: home}},
+			[]Target{{
+				Name:     "t",
+				Platform: &fakePlatform{fail: errors.New("separate mirrors only")},
+				Config: platforms.PlatformConfig{Mirror: platforms.MirrorConfig{
+					Repository: "mirror",
+					Strategy:   platforms.StrategySeparate,
+				}},
+			}},
+			Options{SkipExisting: true, Ledger: ledger, CommitMessage: message, Out: io.Discard, Location: time.UTC},
+		)
+		for repository, platform := range mirrors {
+			engine.instances["t\x00"+repository] = platform
+		}
+
+		if _, err := engine.Sync(ctx, day.Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each source's mirror numbers its day from 1, and carries on from the
+	// ledger in later runs without counting the other mirror's commits
+	sync()
+	work.commits = append(work.commits, platforms.Commit{SHA: "w2", Date: day.Add(2 * time.Hour)})
+	home.commits = append(home.commits, platforms.Commit{SHA: "h2", Date: day.Add(3 * time.Hour)})
+	sync()
+
+	for repository, platform := range mirrors {
+		var indexes []string
+		for _, commit := range platform.mirrored {
+			indexes = append(indexes, commit.Message)
+		}
+		if got := strings.Join(indexes, ","); got != "1,2" {
+			t.Errorf("%s numbered its commits %s, want 1,2", repository, got)
+		}
+	}
+}
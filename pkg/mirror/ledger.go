@@ -0,0 +1,171 @@
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+// ErrLedgerLocked is returned by OpenLedger while another run holds the ledger
+var ErrLedgerLocked = fmt.Errorf("ledger is locked by another run")
+
+// LedgerEntry records one source commit mirrored to one target
+type LedgerEntry struct {
+	Platform   string    `json:"platform"`
+	Repo       string    `json:"repo"`
+	SHA        string    `json:"sha"`
+	Target     string    `json:"target"`
+	MirrorRepo string    `json:"mirror_repo,omitempty"`
+	MirrorSHA  string    `json:"mirror_sha"`
+	Date       time.Time `json:"date"`
+	MirroredAt time.Time `json:"mirrored_at"`
+}
+
+// Ledger is the on-disk record of which source commits have been mirrored
+// where. A lock on a file next to it keeps concurrent runs from interleaving,
+// and every update is written to a temporary file and renamed into place so
+// a crash never leaves a half-written ledger behind.
+type Ledger struct {
+	path    string
+	lock    *os.File
+	entries []LedgerEntry
+	index   map[string]int
+}
+
+// OpenLedger loads the ledger at path, creating it if needed, and locks it
+// for the lifetime of the process. Call Close to release the lock.
+func OpenLedger(path string) (*Ledger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock ledger: %w", err)
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		if errors.Is(err, ErrLedgerLocked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock ledger: %w", err)
+	}
+	// The PID is only informational; the lock itself is what counts
+	if err := lock.Truncate(0); err == nil {
+		fmt.Fprintf(lock, "%d\n", os.Getpid())
+	}
+
+	l := &Ledger{
+		path:  path,
+		lock:  lock,
+		index: make(map[string]int),
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// First run, start with an empty ledger
+	case err != nil:
+		l.Close()
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	default:
+		if err := json.Unmarshal(data, &l.entries); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to parse ledger %s: %w", path, err)
+		}
+	}
+
+	for i, entry := range l.entries {
+		l.index[ledgerKey(entry.Target, entry.Platform, entry.SHA)] = i
+	}
+
+	return l, nil
+}
+
+// Close releases the ledger lock. The lock file itself is left in place so
+// a run waiting to open it never locks a file that is about to be removed.
+func (l *Ledger) Close() error {
+	err := unlockFile(l.lock)
+	if closeErr := l.lock.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unlock ledger: %w", err)
+	}
+	return nil
+}
+
+// Has reports whether a commit has already been mirrored to the target
+func (l *Ledger) Has(target string, commit platforms.Commit) bool {
+	_, ok := l.index[ledgerKey(target, commit.Platform, commit.SHA)]
+	return ok
+}
+
+// DayCounts returns how many commits were mirrored to one mirror repository
+// of a target on each day (YYYY-MM-DD of the source commit date in the given
+// zone). Entries recorded without a mirror repository count toward all of
+// the target's repositories.
+func (l *Ledger) DayCounts(target, repository string, loc *time.Location) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range l.entries {
+		if entry.Target == target && (entry.MirrorRepo == repository || entry.MirrorRepo == "") {
+			counts[entry.Date.In(loc).Format("2006-01-02")]++
+		}
+	}
+	return counts
+}
+
+// Record adds the mirror commits created in a mirror repository of a target
+// and saves the ledger
+func (l *Ledger) Record(target, repository string, commits []platforms.Commit, mirrored []platforms.MirroredCommit) error {
+	if len(mirrored) == 0 {
+		return nil
+	}
+
+	bySHA := make(map[string]platforms.Commit, len(commits))
+	for _, commit := range commits {
+		bySHA[commit.SHA] = commit
+	}
+
+	now := time.Now()
+	for _, m := range mirrored {
+		commit := bySHA[m.SourceSHA]
+		entry := LedgerEntry{
+			Platform:   commit.Platform,
+			Repo:       commit.Repo,
+			SHA:        m.SourceSHA,
+			Target:     target,
+			MirrorRepo: repository,
+			MirrorSHA:  m.MirrorSHA,
+			Date:       commit.Date,
+			MirroredAt: now,
+		}
+
+		key := ledgerKey(target, entry.Platform, entry.SHA)
+		if i, ok := l.index[key]; ok {
+			l.entries[i] = entry
+			continue
+		}
+		l.index[key] = len(l.entries)
+		l.entries = append(l.entries, entry)
+	}
+
+	return l.save()
+}
+
+// save atomically replaces the ledger file with the current entries
+func (l *Ledger) save() error {
+	if err := writeJSONAtomic(l.path, l.entries); err != nil {
+		return fmt.Errorf("failed to save ledger: %w", err)
+	}
+	return nil
+}
+
+func ledgerKey(target, platform, sha string) string {
+	return target + "\x00" + platform + "\x00" + sha
+}
@@ -0,0 +1,34 @@
+package mirror
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenLedgerLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+
+	// A lock file left behind by a crashed run doesn't block anything
+	if err := os.WriteFile(path+".lock", []byte("999999\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger, err := OpenLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenLedger(path); !errors.Is(err, ErrLedgerLocked) {
+		t.Fatalf("second OpenLedger = %v, want ErrLedgerLocked", err)
+	}
+
+	if err := ledger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ledger, err = OpenLedger(path)
+	if err != nil {
+		t.Fatalf("OpenLedger after Close: %v", err)
+	}
+	ledger.Close()
+}
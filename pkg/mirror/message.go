@@ -0,0 +1,116 @@
+package mirror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCommitMessage is used when no commit message template is configured
+const DefaultCommitMessage = "Development work - {date}"
+
+// MessageData holds the values a commit message template can refer to
+type MessageData struct {
+	Date     time.Time // source commit timestamp
+	Source   string    // configured source name
+	Platform string    // source platform type
+	Repo     string    // repository alias, never the real repository name
+	Index    int       // 1-based position of the commit within its day
+}
+
+// placeholders maps each supported placeholder to how it is rendered
+var placeholders = map[string]func(MessageData) string{
+	"date":     func(d MessageData) string { return d.Date.Format("2006-01-02") },
+	"time":     func(d MessageData) string { return d.Date.Format("15:04") },
+	"weekday":  func(d MessageData) string { return d.Date.Format("Monday") },
+	"source":   func(d MessageData) string { return d.Source },
+	"platform": func(d MessageData) string { return d.Platform },
+	"repo":     func(d MessageData) string { return d.Repo },
+	"n":        func(d MessageData) string { return strconv.Itoa(d.Index) },
+}
+
+// MessageTemplate is a parsed commit message template such as
+// "Development work - {date} ({source})". Literal braces are written as
+// "{{" and "}}".
+type MessageTemplate struct {
+	text  string
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal     string
+	placeholder string
+}
+
+// ParseMessageTemplate parses a commit message template, rejecting unknown
+// placeholders and unbalanced braces
+func ParseMessageTemplate(text string) (*MessageTemplate, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("commit message template is empty")
+	}
+
+	t := &MessageTemplate{text: text}
+	var literal strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '{' && strings.HasPrefix(text[i:], "{{"):
+			literal.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(text[i:], "}}"):
+			literal.WriteByte('}')
+			i++
+		case c == '}':
+			return nil, fmt.Errorf("commit message template %q: unexpected '}' at offset %d", text, i)
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("commit message template %q: unclosed '{' at offset %d", text, i)
+			}
+
+			name := text[i+1 : i+end]
+			if _, ok := placeholders[name]; !ok {
+				return nil, fmt.Errorf("commit message template %q: unknown placeholder {%s} (expected one of %s)", text, name, placeholderList())
+			}
+
+			if literal.Len() > 0 {
+				t.parts = append(t.parts, templatePart{literal: literal.String()})
+				literal.Reset()
+			}
+			t.parts = append(t.parts, templatePart{placeholder: name})
+			i += end
+		default:
+			literal.WriteByte(c)
+		}
+	}
+
+	if literal.Len() > 0 {
+		t.parts = append(t.parts, templatePart{literal: literal.String()})
+	}
+
+	return t, nil
+}
+
+// Render fills in the template's placeholders
+func (t *MessageTemplate) Render(data MessageData) string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.placeholder != "" {
+			b.WriteString(placeholders[part.placeholder](data))
+			continue
+		}
+		b.WriteString(part.literal)
+	}
+	return b.String()
+}
+
+// String returns the template text as configured
+func (t *MessageTemplate) String() string {
+	return t.text
+}
+
+// placeholderList returns the supported placeholders in a stable order
+func placeholderList() string {
+	return "{date}, {time}, {weekday}, {source}, {platform}, {repo}, {n}"
+}
@@ -0,0 +1,74 @@
+package mirror
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadNameKey reads the key for hashed names at path, creating a random one
+// on first use. It only keeps names stable on this machine, so hashed mirror
+// repositories, which every machine has to agree on, need sync.name_key
+// instead. Call it while holding the ledger lock.
+func LoadNameKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil && len(bytes.TrimSpace(data)) > 0 {
+		return bytes.TrimSpace(data), nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read name key: %w", err)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate name key: %w", err)
+	}
+	key := []byte(hex.EncodeToString(random))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := writeFileAtomic(path, key); err != nil {
+		return nil, fmt.Errorf("failed to save name key: %w", err)
+	}
+	return key, nil
+}
+
+// writeJSONAtomic writes v as JSON to a temporary file next to path and
+// renames it into place, so readers never see a partially written file
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path, readable by
+// the owner only, and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,37 @@
+package mirror
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLoadNameKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "name.key")
+
+	key, err := LoadNameKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) < 32 {
+		t.Errorf("generated a %d byte key", len(key))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 && runtime.GOOS != "windows" {
+		t.Errorf("name key is readable by others (%v)", mode)
+	}
+
+	again, err := LoadNameKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, key) {
+		t.Errorf("name key changed from %s to %s", key, again)
+	}
+}
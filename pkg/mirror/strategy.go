@@ -0,0 +1,92 @@
+package mirror
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+// ValidateStrategy checks that a mirror strategy is one the engine knows
+func ValidateStrategy(strategy string) error {
+	switch strategy {
+	case "", platforms.StrategyUnified, platforms.StrategySeparate, platforms.StrategyHashed:
+		return nil
+	default:
+		return fmt.Errorf("unknown mirror strategy %q (expected unified, separate or hashed)", strategy)
+	}
+}
+
+// mirrorRepository returns the mirror repository a source's commits go to
+// on a target:
+//
+//	unified:  <repository>
+//	separate: <repository>-<source>
+//	hashed:   <repository>-<10 hex chars derived from the source name>
+//
+// Hashed names are keyed with the name key, so they can't be matched against
+// a list of likely source names.
+func (e *Engine) mirrorRepository(target Target, source string) string {
+	mirror := target.Config.Mirror
+
+	switch mirror.Strategy {
+	case platforms.StrategySeparate:
+		return mirror.Repository + "-" + source
+	case platforms.StrategyHashed:
+		return mirror.Repository + "-" + e.hashName(source)
+	default:
+		return mirror.Repository
+	}
+}
+
+// hashName derives a short, stable, obfuscated name keyed with the name key.
+// The trailer key isn't used: it may be rotated, and that must not rename
+// mirrors.
+func (e *Engine) hashName(name string) string {
+	mac := hmac.New(sha256.New, e.opts.NameKey)
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))[:10]
+}
+
+// mirrorRepositories lists the mirror repositories a target needs for the
+// sources taking part in this run, in source order
+func (e *Engine) mirrorRepositories(target Target) []string {
+	var repositories []string
+	seen := make(map[string]bool)
+
+	for _, source := range e.sources {
+		repository := e.mirrorRepository(target, source.Name)
+		if !seen[repository] {
+			seen[repository] = true
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories
+}
+
+// targetPlatform returns the platform instance writing to one mirror
+// repository of a target, creating it on first use
+func (e *Engine) targetPlatform(target Target, repository string) (platforms.GitPlatform, error) {
+	if repository == target.Config.Mirror.Repository {
+		return target.Platform, nil
+	}
+
+	key := target.Name + "\x00" + repository
+	if platform, ok := e.instances[key]; ok {
+		return platform, nil
+	}
+
+	config := target.Config
+	config.Mirror.Repository = repository
+
+	platform, err := platforms.NewPlatform(config.Platform, config)
+	if err != nil {
+		return nil, err
+	}
+
+	e.instances[key] = platform
+	return platform, nil
+}
@@ -0,0 +1,43 @@
+package mirror
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// trailerName is the git trailer that carries a mirror commit's fingerprint
+const trailerName = "Mirror-Id"
+
+// Fingerprint returns a keyed HMAC-SHA256 of a source commit SHA, truncated
+// to 128 bits. Without the key it can't be linked back to the source commit,
+// so it is safe to publish on a mirror.
+func Fingerprint(key []byte, sha string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sha))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// withTrailer appends a fingerprint trailer to a commit message
+func withTrailer(message, fingerprint string) string {
+	return strings.TrimRight(message, "\n") + "\n\n" + trailerName + ": " + fingerprint
+}
+
+// parseTrailer extracts the fingerprint trailer from a mirror commit message
+func parseTrailer(message string) (string, bool) {
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+
+	// Trailers live in the last paragraph of the message
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, trailerName+":"); ok {
+			return strings.TrimSpace(value), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,272 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestAzureDevOps returns an Azure DevOps platform talking to a fake API
+// for the organization "org"
+func newTestAzureDevOps(t *testing.T, config PlatformConfig, handler http.Handler) *AzureDevOpsPlatform {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config.Host = server.URL
+	if config.Extra == nil {
+		config.Extra = map[string]interface{}{}
+	}
+	config.Extra["organization"] = "org"
+
+	a, err := NewAzureDevOpsPlatform(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// azureCommitJSON returns a commit as the commits API lists it
+func azureCommitJSON(id, name, email string) map[string]interface{} {
+	person := map[string]interface{}{"name": name, "email": email, "date": "2024-03-01T10:00:00Z"}
+	return map[string]interface{}{"commitId": id, "comment": "work", "author": person, "committer": person}
+}
+
+func TestAzureDevOpsGetCommitsPages(t *testing.T) {
+	const total = 230
+
+	var skips []string
+	a := newTestAzureDevOps(t, PlatformConfig{
+		Auth:       AuthConfig{Token: "token"},
+		Identities: Identities{Emails: []string{"jane@example.com"}},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/project/_apis/git/repositories/repo/commits" {
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		query := r.URL.Query()
+		skip, _ := strconv.Atoi(query.Get("searchCriteria.$skip"))
+		top, _ := strconv.Atoi(query.Get("searchCriteria.$top"))
+		skips = append(skips, query.Get("searchCriteria.$skip"))
+
+		var page []interface{}
+		for i := skip; i < total && i < skip+top; i++ {
+			page = append(page, azureCommitJSON(fmt.Sprintf("c%d", i), "Jane Doe", "jane@example.com"))
+		}
+		writeJSON(t, w, map[string]interface{}{"count": len(page), "value": page})
+	}))
+
+	commits, err := a.GetCommits(context.Background(), Repository{FullName: "project/repo"}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != total {
+		t.Errorf("got %d commits, want %d", len(commits), total)
+	}
+	if got := strings.Join(skips, ","); got != "0,100,200" {
+		t.Errorf("requested pages at %s, want 0,100,200", got)
+	}
+}
+
+func TestAzureDevOpsGetCommitsFiltersByAuthor(t *testing.T) {
+	var authors []string
+	a := newTestAzureDevOps(t, PlatformConfig{
+		Auth:       AuthConfig{Token: "token"},
+		Identities: Identities{Names: []string{"Jane Doe"}, Emails: []string{"jane@example.com"}},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("searchCriteria.fromDate") != "2024-01-01T00:00:00Z" {
+			t.Errorf("commits requested from %q", query.Get("searchCriteria.fromDate"))
+		}
+
+		author := query.Get("searchCriteria.author")
+		authors = append(authors, author)
+
+		var commits []interface{}
+		switch author {
+		case "Jane Doe":
+			commits = []interface{}{
+				azureCommitJSON("a", "Jane Doe", "jane@example.com"),
+				// The API matches names partially
+				azureCommitJSON("b", "Jane Doe-Smith", "jds@example.com"),
+			}
+		case "jane@example.com":
+			commits = []interface{}{
+				azureCommitJSON("a", "Jane Doe", "jane@example.com"),
+				azureCommitJSON("c", "jane", "jane@example.com"),
+			}
+		default:
+			t.Errorf("unfiltered or unexpected request %s", r.URL)
+		}
+		writeJSON(t, w, map[string]interface{}{"count": len(commits), "value": commits})
+	}))
+
+	commits, err := a.GetCommits(context.Background(), Repository{FullName: "project/repo"}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var shas []string
+	for _, commit := range commits {
+		shas = append(shas, commit.SHA)
+	}
+	sort.Strings(shas)
+	if got := strings.Join(shas, ","); got != "a,c" {
+		t.Errorf("commits = %s, want a,c", got)
+	}
+	if got := strings.Join(authors, ","); got != "Jane Doe,jane@example.com" {
+		t.Errorf("searched authors %s, want Jane Doe,jane@example.com", got)
+	}
+	if excluded := a.ExcludedCommits(); excluded != 1 {
+		t.Errorf("excluded %d commits, want 1", excluded)
+	}
+}
+
+func TestAzureDevOpsMirrorCommits(t *testing.T) {
+	tests := []struct {
+		name    string
+		head    string // current mirror branch head ("": no branch)
+		extra   map[string]interface{}
+		changes string // change type of each pushed commit
+		author  string
+	}{
+		{"new branch", "", map[string]interface{}{"email": "jane@example.com"}, "add,edit,edit", "Jane Doe <jane@example.com>"},
+		{"existing branch", "head", nil, "edit,edit,edit", "Jane Doe <jane@example.com>"},
+	}
+
+	zone := time.FixedZone("", -3*60*60)
+	commits := []Commit{
+		{SHA: "a", Message: "Development work", Date: time.Date(2024, 3, 1, 9, 0, 0, 0, zone)},
+		{SHA: "b", Message: "Development work", Date: time.Date(2024, 3, 1, 12, 0, 0, 0, zone)},
+		{SHA: "c", Message: "Development work", Date: time.Date(2024, 3, 2, 18, 30, 0, 0, zone)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushes []map[string]interface{}
+			a := newTestAzureDevOps(t, PlatformConfig{
+				Auth:   AuthConfig{Username: "jane", Token: "token"},
+				Mirror: MirrorConfig{Repository: "project/mirror", Branch: "main"},
+				Extra:  tt.extra,
+			}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				const repo = "/org/project/_apis/git/repositories/mirror"
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == repo+"/refs":
+					var refs []interface{}
+					if tt.head != "" {
+						refs = append(refs, map[string]string{"name": "refs/heads/main", "objectId": tt.head})
+					}
+					writeJSON(t, w, map[string]interface{}{"count": len(refs), "value": refs})
+				case r.Method == http.MethodGet && r.URL.Path == repo+"/items":
+					writeJSON(t, w, map[string]string{"path": "/.activity"})
+				case r.Method == http.MethodGet && r.URL.Path == "/org/_apis/connectionData":
+					writeJSON(t, w, map[string]interface{}{"authenticatedUser": map[string]interface{}{
+						"providerDisplayName": "Jane Doe",
+						"properties":          map[string]interface{}{"Account": map[string]string{"$type": "System.String", "$value": "jane@example.com"}},
+					}})
+				case r.Method == http.MethodPost && r.URL.Path == repo+"/pushes":
+					var push map[string]interface{}
+					if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+						t.Error(err)
+						return
+					}
+					pushes = append(pushes, push)
+
+					pushed, _ := push["commits"].([]interface{})
+					var result []interface{}
+					for i := range pushed {
+						result = append(result, map[string]string{"commitId": fmt.Sprintf("m%d", i+1)})
+					}
+					writeJSON(t, w, map[string]interface{}{"commits": result})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusForbidden)
+				}
+			}))
+
+			mirrored, err := a.MirrorCommits(context.Background(), commits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, m := range mirrored {
+				if m.SourceSHA != commits[i].SHA || m.MirrorSHA != fmt.Sprintf("m%d", i+1) {
+					t.Errorf("mirrored %d = %+v", i, m)
+				}
+			}
+
+			// All commits go in one push, chained onto the current head
+			if len(pushes) != 1 {
+				t.Fatalf("%d pushes, want 1", len(pushes))
+			}
+			refUpdates, _ := pushes[0]["refUpdates"].([]interface{})
+			if len(refUpdates) != 1 {
+				t.Fatalf("push updates %d refs, want 1", len(refUpdates))
+			}
+			oldObjectID := tt.head
+			if oldObjectID == "" {
+				oldObjectID = azureZeroObjectID
+			}
+			if ref, _ := refUpdates[0].(map[string]interface{}); ref["name"] != "refs/heads/main" || ref["oldObjectId"] != oldObjectID {
+				t.Errorf("push updates %v, want refs/heads/main from %s", ref, oldObjectID)
+			}
+
+			pushed, _ := pushes[0]["commits"].([]interface{})
+			if len(pushed) != len(commits) {
+				t.Fatalf("pushed %d commits, want %d", len(pushed), len(commits))
+			}
+			var changes []string
+			for i, p := range pushed {
+				commit, _ := p.(map[string]interface{})
+				change, _ := commit["changes"].([]interface{})[0].(map[string]interface{})
+				changes = append(changes, fmt.Sprint(change["changeType"]))
+
+				for _, role := range []string{"author", "committer"} {
+					person, _ := commit[role].(map[string]interface{})
+					if got := fmt.Sprintf("%s <%s>", person["name"], person["email"]); got != tt.author {
+						t.Errorf("commit %d %s = %s, want %s", i+1, role, got, tt.author)
+					}
+					date, _ := time.Parse(time.RFC3339, fmt.Sprint(person["date"]))
+					if !date.Equal(commits[i].Date) {
+						t.Errorf("commit %d %s date = %v, want %s", i+1, role, person["date"], commits[i].Date)
+					}
+				}
+			}
+			if got := strings.Join(changes, ","); got != tt.changes {
+				t.Errorf("changes = %s, want %s", got, tt.changes)
+			}
+		})
+	}
+}
+
+func TestAzureDevOpsMirrorCommitsRequiresEmail(t *testing.T) {
+	a := newTestAzureDevOps(t, PlatformConfig{
+		Auth:   AuthConfig{Username: "jane", Token: "token"},
+		Mirror: MirrorConfig{Repository: "project/mirror", Branch: "main"},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/org/project/_apis/git/repositories/mirror/refs":
+			writeJSON(t, w, map[string]interface{}{"count": 0, "value": []interface{}{}})
+		case "/org/_apis/connectionData":
+			writeJSON(t, w, map[string]interface{}{"authenticatedUser": map[string]interface{}{"providerDisplayName": "Jane Doe"}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+
+	_, err := a.MirrorCommits(context.Background(), []Commit{{SHA: "a", Message: "work", Date: time.Now()}})
+	if err == nil || !strings.Contains(err.Error(), "extra.email") {
+		t.Errorf("MirrorCommits() = %v, want an error asking for extra.email", err)
+	}
+}
@@ -0,0 +1,478 @@
+package platforms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// bitbucketCloudAPI is the Bitbucket Cloud 2.0 REST API base URL
+const bitbucketCloudAPI = "https://api.bitbucket.org/2.0"
+
+// BitbucketPlatform implements GitPlatform for Bitbucket Cloud
+type BitbucketPlatform struct {
+	*identityFilter
+	api       *restClient
+	config    PlatformConfig
+	workspace string
+}
+
+// NewBitbucketPlatform creates a new Bitbucket Cloud platform instance. The
+// workspace comes from extra.workspace and defaults to the auth username.
+// Commits are limited to the configured identities, defaulting to the auth
+// username.
+func NewBitbucketPlatform(config PlatformConfig) (*BitbucketPlatform, error) {
+	workspace := extraString(config.Extra, "workspace")
+	if workspace == "" {
+		workspace = config.Auth.Username
+	}
+
+	b := &BitbucketPlatform{
+		identityFilter: newIdentityFilter(config),
+		config:         config,
+		workspace:      workspace,
+	}
+	b.api = newRESTClient(bitbucketBaseURL(config.Host), config.RateLimitWait, config.Timeout, b.authorize)
+
+	return b, nil
+}
+
+// bitbucketBaseURL returns the API base URL for a host. A host with a scheme
+// is used as the API base as-is (e.g. a proxy or a local fake, together with
+// extra.edition: cloud).
+func bitbucketBaseURL(host string) string {
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return strings.TrimSuffix(host, "/")
+	}
+	return bitbucketCloudAPI
+}
+
+// Connect establishes connection to Bitbucket Cloud
+func (b *BitbucketPlatform) Connect(ctx context.Context, config AuthConfig) error {
+	b.config.Auth = config
+	if extraString(b.config.Extra, "workspace") == "" {
+		b.workspace = config.Username
+	}
+	return nil
+}
+
+// ValidateCredentials validates the Bitbucket credentials
+func (b *BitbucketPlatform) ValidateCredentials(ctx context.Context) error {
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := b.api.get(ctx, "/user", nil, &user); err != nil {
+		return fmt.Errorf("invalid Bitbucket credentials: %w", err)
+	}
+
+	if b.workspace == "" {
+		b.workspace = user.Username
+	}
+	return nil
+}
+
+// Disconnect closes any connections (no-op for Bitbucket API)
+func (b *BitbucketPlatform) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+// bitbucketRepository is a repository in Bitbucket API responses
+type bitbucketRepository struct {
+	UUID        string    `json:"uuid"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	IsPrivate   bool      `json:"is_private"`
+	CreatedOn   time.Time `json:"created_on"`
+	UpdatedOn   time.Time `json:"updated_on"`
+	Links       struct {
+		HTML  bitbucketLink   `json:"html"`
+		Clone []bitbucketLink `json:"clone"`
+	} `json:"links"`
+}
+
+// bitbucketCommit is a commit in Bitbucket API responses
+type bitbucketCommit struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+	Author  struct {
+		Raw  string `json:"raw"`
+		User struct {
+			DisplayName string `json:"display_name"`
+			Nickname    string `json:"nickname"`
+		} `json:"user"`
+	} `json:"author"`
+	Links struct {
+		HTML bitbucketLink `json:"html"`
+	} `json:"links"`
+}
+
+// cloneURL returns the HTTPS clone link of a repository, without the user
+// name Bitbucket puts in it, or "" if there is none
+func (r bitbucketRepository) cloneURL() string {
+	for _, link := range r.Links.Clone {
+		if link.Name != "https" {
+			continue
+		}
+		u, err := url.Parse(link.Href)
+		if err != nil {
+			return link.Href
+		}
+		u.User = nil
+		return u.String()
+	}
+	return ""
+}
+
+type bitbucketLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+// bitbucketPage is one page of a paginated Bitbucket response
+type bitbucketPage struct {
+	Values json.RawMessage `json:"values"`
+	Next   string          `json:"next"`
+}
+
+// ListRepositories returns all repositories in the workspace
+func (b *BitbucketPlatform) ListRepositories(ctx context.Context) ([]Repository, error) {
+	var allRepos []Repository
+
+	if b.workspace == "" {
+		return nil, fmt.Errorf("no Bitbucket workspace configured - set extra.workspace or auth.username")
+	}
+
+	next := b.api.url("/repositories/"+url.PathEscape(b.workspace), url.Values{"pagelen": {"100"}})
+	for next != "" {
+		var repos []bitbucketRepository
+		var err error
+		next, err = b.page(ctx, next, &repos)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+
+		for _, repo := range repos {
+			allRepos = append(allRepos, Repository{
+				ID:          repo.UUID,
+				Name:        repo.Slug,
+				FullName:    repo.FullName,
+				Description: repo.Description,
+				URL:         repo.Links.HTML.Href,
+				CloneURL:    repo.cloneURL(),
+				Private:     repo.IsPrivate,
+				CreatedAt:   repo.CreatedOn,
+				UpdatedAt:   repo.UpdatedOn,
+				Platform:    "bitbucket",
+			})
+		}
+	}
+
+	return allRepos, nil
+}
+
+// GetCommits retrieves commits by the configured identities from a
+// repository since a specific date
+func (b *BitbucketPlatform) GetCommits(ctx context.Context, repo Repository, since time.Time) ([]Commit, error) {
+	fullName := repo.FullName
+	if !strings.Contains(fullName, "/") {
+		fullName = b.workspace + "/" + fullName
+	}
+
+	commits, err := b.listCommits(ctx, b.repoPath(fullName)+"/commits", since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	allCommits := make([]Commit, 0, len(commits))
+	for _, commit := range commits {
+		author := parseRawAuthor(commit.Author.Raw)
+		if author.Name == "" {
+			author.Name = commit.Author.User.DisplayName
+		}
+		author.Username = commit.Author.User.Nickname
+
+		allCommits = append(allCommits, Commit{
+			SHA:       commit.Hash,
+			Message:   commit.Message,
+			Author:    author,
+			Committer: author, // Bitbucket only reports the author
+			Date:      commit.Date,
+			URL:       commit.Links.HTML.Href,
+			Repo:      fullName,
+			Platform:  "bitbucket",
+		})
+	}
+
+	return b.keep(allCommits)
+}
+
+// listCommits pages through a commit listing, newest first, until a page
+// holds nothing at or after since. Listings follow the commit graph rather
+// than dates, so a single older commit doesn't end the walk.
+func (b *BitbucketPlatform) listCommits(ctx context.Context, endpoint string, since time.Time) ([]bitbucketCommit, error) {
+	var allCommits []bitbucketCommit
+
+	next := b.api.url(endpoint, url.Values{"pagelen": {"100"}})
+	for next != "" {
+		var commits []bitbucketCommit
+		var err error
+		next, err = b.page(ctx, next, &commits)
+		if err != nil {
+			return nil, err
+		}
+
+		recent := false
+		for _, commit := range commits {
+			if commit.Date.Before(since) {
+				continue
+			}
+			recent = true
+			allCommits = append(allCommits, commit)
+		}
+
+		if !recent {
+			break
+		}
+	}
+
+	return allCommits, nil
+}
+
+// GetCommitCount returns the number of commits since a specific date
+func (b *BitbucketPlatform) GetCommitCount(ctx context.Context, repo Repository, since time.Time) (int, error) {
+	commits, err := b.GetCommits(ctx, repo, since)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits), nil
+}
+
+// InitializeMirror creates a new repository for mirroring
+func (b *BitbucketPlatform) InitializeMirror(ctx context.Context, name string, visibility string) error {
+	repoPath := b.repoPath(b.mirrorRepository(name))
+
+	// Bitbucket answers a duplicate create with a generic 400, so look first
+	err := b.api.get(ctx, repoPath, nil, nil)
+	if err == nil {
+		return nil // Repo already exists, that's okay
+	}
+	if !isStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("failed to look up mirror repository: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"scm":         "git",
+		"is_private":  visibility != "public",
+		"description": "Mirror of git activity from other platforms",
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.api.url(repoPath, nil), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if _, err := b.api.do(req, nil); err != nil && !errors.Is(err, ErrAlreadyExists) {
+		return fmt.Errorf("failed to create mirror repository: %w", err)
+	}
+
+	return nil
+}
+
+// MirrorCommits creates empty commits with exact author and committer dates
+// locally and pushes them to the mirror branch over HTTPS. The /src endpoint
+// can't set dates, so it isn't used for writing.
+func (b *BitbucketPlatform) MirrorCommits(ctx context.Context, commits []Commit) ([]MirroredCommit, error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	var repo bitbucketRepository
+	if err := b.api.get(ctx, b.repoPath(b.mirrorRepository(b.config.Mirror.Repository)), nil, &repo); err != nil {
+		return nil, fmt.Errorf("failed to get mirror repository: %w", err)
+	}
+	remote := repo.cloneURL()
+	if remote == "" {
+		return nil, fmt.Errorf("mirror repository %s has no HTTPS clone link", repo.FullName)
+	}
+
+	// Access tokens push as a fixed user; app passwords and API tokens as
+	// the account they belong to
+	auth := b.config.Auth
+	if auth.Username == "" {
+		auth.Username = "x-token-auth"
+	}
+
+	// Pushing to an empty repository or missing branch starts it with an
+	// orphan commit
+	name, email := mirrorIdentity(ctx, b.config)
+	m := newGitMirror(remote, b.mirrorBranch(), mirrorCacheDir(b.config.Extra), auth, name, email, b.config.Timeout)
+	return m.commit(ctx, commits)
+}
+
+// ListMirrorCommits returns the commits already on the mirror branch since a specific date
+func (b *BitbucketPlatform) ListMirrorCommits(ctx context.Context, since time.Time) ([]Commit, error) {
+	fullName := b.mirrorRepository(b.config.Mirror.Repository)
+	endpoint := b.repoPath(fullName) + "/commits/" + url.PathEscape(b.mirrorBranch())
+
+	commits, err := b.listCommits(ctx, endpoint, since)
+	if err != nil {
+		// An empty repository has no mirror branch yet
+		if isStatus(err, http.StatusNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list mirror commits: %w", err)
+	}
+
+	allCommits := make([]Commit, 0, len(commits))
+	for _, commit := range commits {
+		allCommits = append(allCommits, Commit{
+			SHA:      commit.Hash,
+			Message:  commit.Message,
+			Date:     commit.Date,
+			URL:      commit.Links.HTML.Href,
+			Repo:     fullName,
+			Platform: "bitbucket",
+		})
+	}
+
+	return allCommits, nil
+}
+
+// GetMirrorStatus returns the status of the mirror repository
+func (b *BitbucketPlatform) GetMirrorStatus(ctx context.Context) (MirrorStatus, error) {
+	repoPath := b.repoPath(b.mirrorRepository(b.config.Mirror.Repository))
+
+	var repo bitbucketRepository
+	if err := b.api.get(ctx, repoPath, nil, &repo); err != nil {
+		return MirrorStatus{}, fmt.Errorf("failed to get mirror repository: %w", err)
+	}
+
+	// Get latest commit
+	var latest bitbucketPage
+	err := b.api.get(ctx, repoPath+"/commits/"+url.PathEscape(b.mirrorBranch()), url.Values{"pagelen": {"1"}}, &latest)
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return MirrorStatus{}, fmt.Errorf("failed to get latest commit: %w", err)
+	}
+
+	status := MirrorStatus{
+		Repository:   repo.FullName,
+		LastSync:     time.Now(), // This would need to be tracked separately
+		TotalCommits: 0,          // Would need to count commits
+		Status:       "active",
+	}
+
+	var commits []bitbucketCommit
+	if len(latest.Values) > 0 {
+		if err := json.Unmarshal(latest.Values, &commits); err != nil {
+			return MirrorStatus{}, fmt.Errorf("failed to decode latest commit: %w", err)
+		}
+	}
+	if len(commits) > 0 {
+		status.LastCommitSHA = commits[0].Hash
+	}
+
+	return status, nil
+}
+
+// mirrorRepository returns the workspace/slug of a mirror repository name,
+// defaulting to the configured workspace
+func (b *BitbucketPlatform) mirrorRepository(name string) string {
+	if strings.Contains(name, "/") {
+		return strings.ToLower(name)
+	}
+	return b.workspace + "/" + strings.ToLower(name)
+}
+
+// mirrorBranch returns the configured mirror branch
+func (b *BitbucketPlatform) mirrorBranch() string {
+	if b.config.Mirror.Branch != "" {
+		return b.config.Mirror.Branch
+	}
+	return "main"
+}
+
+// repoPath returns the API path of a workspace/slug repository
+func (b *BitbucketPlatform) repoPath(fullName string) string {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		return "/repositories/" + url.PathEscape(b.workspace) + "/" + url.PathEscape(fullName)
+	}
+	return "/repositories/" + url.PathEscape(parts[0]) + "/" + url.PathEscape(parts[1])
+}
+
+// page fetches one page of a paginated listing into values and returns the
+// URL of the next page, if any
+func (b *BitbucketPlatform) page(ctx context.Context, pageURL string, values interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var page bitbucketPage
+	if _, err := b.api.do(req, &page); err != nil {
+		return "", err
+	}
+	if len(page.Values) > 0 {
+		if err := json.Unmarshal(page.Values, values); err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return page.Next, nil
+}
+
+// authorize adds the configured credentials to a request
+func (b *BitbucketPlatform) authorize(req *http.Request) {
+	auth := b.config.Auth
+	switch {
+	case auth.Username != "" && auth.Token != "":
+		// App passwords and API tokens use basic auth
+		req.SetBasicAuth(auth.Username, auth.Token)
+	case auth.Username != "" && auth.Password != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case auth.Token != "":
+		// Workspace and repository access tokens are bearer tokens
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	}
+}
+
+// GetPlatformName returns the platform name
+func (b *BitbucketPlatform) GetPlatformName() string {
+	return "Bitbucket"
+}
+
+// GetPlatformType returns the platform type
+func (b *BitbucketPlatform) GetPlatformType() PlatformType {
+	return PlatformBitbucket
+}
+
+// SupportsWebhooks returns true if the platform supports webhooks
+func (b *BitbucketPlatform) SupportsWebhooks() bool {
+	return true
+}
+
+// parseRawAuthor splits a raw "Name <email>" author string
+func parseRawAuthor(raw string) Author {
+	start := strings.LastIndex(raw, "<")
+	end := strings.LastIndex(raw, ">")
+	if start < 0 || end < start {
+		return Author{Name: strings.TrimSpace(raw)}
+	}
+	return Author{
+		Name:  strings.TrimSpace(raw[:start]),
+		Email: strings.TrimSpace(raw[start+1 : end]),
+	}
+}
@@ -0,0 +1,352 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BitbucketServerPlatform implements GitPlatform for self-hosted Bitbucket
+// Server and Data Center. It can only be used as a mirror source.
+type BitbucketServerPlatform struct {
+	*identityFilter
+	api    *restClient
+	config PlatformConfig
+}
+
+// NewBitbucketServerPlatform creates a new Bitbucket Server platform instance.
+// Commits are limited to the configured identities, defaulting to the auth
+// username.
+func NewBitbucketServerPlatform(config PlatformConfig) (*BitbucketServerPlatform, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("Bitbucket Server requires a host")
+	}
+
+	host := config.Host
+	if !strings.HasPrefix(host, "http") {
+		host = "https://" + host
+	}
+
+	b := &BitbucketServerPlatform{
+		identityFilter: newIdentityFilter(config),
+		config:         config,
+	}
+	b.api = newRESTClient(strings.TrimSuffix(host, "/")+"/rest/api/1.0", config.RateLimitWait, config.Timeout, b.authorize)
+
+	return b, nil
+}
+
+// isBitbucketCloud reports whether a bitbucket platform config points at
+// Bitbucket Cloud rather than a Bitbucket Server instance. extra.edition
+// ("cloud" or "server") overrides detection by host.
+func isBitbucketCloud(config PlatformConfig) bool {
+	switch strings.ToLower(extraString(config.Extra, "edition")) {
+	case "cloud":
+		return true
+	case "server", "datacenter":
+		return false
+	}
+
+	host := strings.TrimPrefix(strings.TrimPrefix(config.Host, "https://"), "http://")
+	host = strings.TrimSuffix(host, "/")
+	return host == "" || host == "bitbucket.org" || host == "api.bitbucket.org"
+}
+
+// CanMirror reports whether a platform configuration can be used as a mirror
+// target. Bitbucket Server instances are source-only.
+func CanMirror(config PlatformConfig) bool {
+	return config.Platform != PlatformBitbucket || isBitbucketCloud(config)
+}
+
+// Connect establishes connection to Bitbucket Server
+func (b *BitbucketServerPlatform) Connect(ctx context.Context, config AuthConfig) error {
+	b.config.Auth = config
+	return nil
+}
+
+// ValidateCredentials validates the Bitbucket Server credentials
+func (b *BitbucketServerPlatform) ValidateCredentials(ctx context.Context) error {
+	var page bitbucketServerPage
+	if err := b.api.get(ctx, "/projects", url.Values{"limit": {"1"}}, &page); err != nil {
+		return fmt.Errorf("invalid Bitbucket Server credentials: %w", err)
+	}
+	return nil
+}
+
+// Disconnect closes any connections (no-op for Bitbucket Server API)
+func (b *BitbucketServerPlatform) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+// bitbucketServerPage is one page of a paginated Bitbucket Server response
+type bitbucketServerPage struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+}
+
+// bitbucketServerProject is a project in Bitbucket Server responses
+type bitbucketServerProject struct {
+	Key string `json:"key"`
+}
+
+// bitbucketServerRepository is a repository in Bitbucket Server responses
+type bitbucketServerRepository struct {
+	ID      int                    `json:"id"`
+	Slug    string                 `json:"slug"`
+	Name    string                 `json:"name"`
+	Public  bool                   `json:"public"`
+	Project bitbucketServerProject `json:"project"`
+	Links   struct {
+		Self  []bitbucketLink `json:"self"`
+		Clone []bitbucketLink `json:"clone"`
+	} `json:"links"`
+}
+
+// bitbucketServerUser is a commit author or committer
+type bitbucketServerUser struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Slug         string `json:"slug"`
+}
+
+// bitbucketServerCommit is a commit in Bitbucket Server responses
+type bitbucketServerCommit struct {
+	ID                 string              `json:"id"`
+	Message            string              `json:"message"`
+	Author             bitbucketServerUser `json:"author"`
+	AuthorTimestamp    int64               `json:"authorTimestamp"`
+	Committer          bitbucketServerUser `json:"committer"`
+	CommitterTimestamp int64               `json:"committerTimestamp"`
+}
+
+// ListRepositories returns the repositories of every project visible to the user
+func (b *BitbucketServerPlatform) ListRepositories(ctx context.Context) ([]Repository, error) {
+	var allRepos []Repository
+
+	var projects []bitbucketServerProject
+	err := b.pages(ctx, "/projects", nil, func(values json.RawMessage) (bool, error) {
+		var page []bitbucketServerProject
+		if err := json.Unmarshal(values, &page); err != nil {
+			return false, err
+		}
+		projects = append(projects, page...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	for _, project := range projects {
+		endpoint := "/projects/" + url.PathEscape(project.Key) + "/repos"
+		err := b.pages(ctx, endpoint, nil, func(values json.RawMessage) (bool, error) {
+			var repos []bitbucketServerRepository
+			if err := json.Unmarshal(values, &repos); err != nil {
+				return false, err
+			}
+
+			for _, repo := range repos {
+				allRepos = append(allRepos, b.repository(repo))
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of project %s: %w", project.Key, err)
+		}
+	}
+
+	return allRepos, nil
+}
+
+// repository converts a Bitbucket Server repository
+func (b *BitbucketServerPlatform) repository(repo bitbucketServerRepository) Repository {
+	var webURL, cloneURL string
+	if len(repo.Links.Self) > 0 {
+		webURL = repo.Links.Self[0].Href
+	}
+	for _, link := range repo.Links.Clone {
+		if link.Name == "http" || link.Name == "https" {
+			cloneURL = link.Href
+		}
+	}
+
+	return Repository{
+		ID:       strconv.Itoa(repo.ID),
+		Name:     repo.Slug,
+		FullName: repo.Project.Key + "/" + repo.Slug,
+		URL:      webURL,
+		CloneURL: cloneURL,
+		Private:  !repo.Public,
+		Platform: "bitbucket",
+	}
+}
+
+// GetCommits retrieves commits by the configured identities from a repository's
+// default branch since a specific date
+func (b *BitbucketServerPlatform) GetCommits(ctx context.Context, repo Repository, since time.Time) ([]Commit, error) {
+	var allCommits []Commit
+
+	parts := strings.Split(repo.FullName, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository full name: %s (expected PROJECT/repo)", repo.FullName)
+	}
+	endpoint := "/projects/" + url.PathEscape(parts[0]) + "/repos/" + url.PathEscape(parts[1]) + "/commits"
+
+	// Commits come newest first in graph order; stop once a page holds
+	// nothing at or after since
+	err := b.pages(ctx, endpoint, nil, func(values json.RawMessage) (bool, error) {
+		var commits []bitbucketServerCommit
+		if err := json.Unmarshal(values, &commits); err != nil {
+			return false, err
+		}
+
+		recent := false
+		for _, commit := range commits {
+			date := time.UnixMilli(commit.AuthorTimestamp)
+			committed := time.UnixMilli(commit.CommitterTimestamp)
+			if committed.Before(since) {
+				continue
+			}
+			recent = true
+
+			allCommits = append(allCommits, Commit{
+				SHA:     commit.ID,
+				Message: commit.Message,
+				Author: Author{
+					Name:     commit.Author.displayName(),
+					Email:    commit.Author.EmailAddress,
+					Username: commit.Author.Slug,
+				},
+				Committer: Author{
+					Name:     commit.Committer.displayName(),
+					Email:    commit.Committer.EmailAddress,
+					Username: commit.Committer.Slug,
+				},
+				Date:      date,
+				Committed: committed,
+				URL:       b.commitURL(repo, commit.ID),
+				Repo:      repo.FullName,
+				Platform:  "bitbucket",
+			})
+		}
+		return recent, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	return b.keep(allCommits)
+}
+
+// commitURL returns the web URL of a commit, derived from the repository link
+func (b *BitbucketServerPlatform) commitURL(repo Repository, sha string) string {
+	if repo.URL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(repo.URL, "/browse"), "/") + "/commits/" + sha
+}
+
+// displayName prefers the human readable name of a user
+func (u bitbucketServerUser) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Name
+}
+
+// GetCommitCount returns the number of commits since a specific date
+func (b *BitbucketServerPlatform) GetCommitCount(ctx context.Context, repo Repository, since time.Time) (int, error) {
+	commits, err := b.GetCommits(ctx, repo, since)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits), nil
+}
+
+// InitializeMirror is not supported: Bitbucket Server is source-only
+func (b *BitbucketServerPlatform) InitializeMirror(ctx context.Context, name string, visibility string) error {
+	return b.sourceOnly()
+}
+
+// MirrorCommits is not supported: Bitbucket Server is source-only
+func (b *BitbucketServerPlatform) MirrorCommits(ctx context.Context, commits []Commit) ([]MirroredCommit, error) {
+	return nil, b.sourceOnly()
+}
+
+// ListMirrorCommits is not supported: Bitbucket Server is source-only
+func (b *BitbucketServerPlatform) ListMirrorCommits(ctx context.Context, since time.Time) ([]Commit, error) {
+	return nil, b.sourceOnly()
+}
+
+// GetMirrorStatus is not supported: Bitbucket Server is source-only
+func (b *BitbucketServerPlatform) GetMirrorStatus(ctx context.Context) (MirrorStatus, error) {
+	return MirrorStatus{}, b.sourceOnly()
+}
+
+func (b *BitbucketServerPlatform) sourceOnly() error {
+	return fmt.Errorf("%s can only be used as a mirror source", b.GetPlatformName())
+}
+
+// pages walks a paginated listing, calling visit with each page's values
+// until the last page or until visit returns false
+func (b *BitbucketServerPlatform) pages(ctx context.Context, endpoint string, query url.Values, visit func(json.RawMessage) (bool, error)) error {
+	params := url.Values{"limit": {"100"}}
+	for key, values := range query {
+		params[key] = values
+	}
+
+	for start := 0; ; {
+		params.Set("start", strconv.Itoa(start))
+
+		var page bitbucketServerPage
+		if err := b.api.get(ctx, endpoint, params, &page); err != nil {
+			return err
+		}
+
+		more := true
+		if len(page.Values) > 0 {
+			var err error
+			if more, err = visit(page.Values); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+
+		if !more || page.IsLastPage || page.NextPageStart <= start {
+			return nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// authorize adds the configured credentials to a request
+func (b *BitbucketServerPlatform) authorize(req *http.Request) {
+	auth := b.config.Auth
+	switch {
+	case auth.Token != "":
+		// Personal access tokens are bearer tokens
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case auth.Username != "" && auth.Password != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+}
+
+// GetPlatformName returns the platform name
+func (b *BitbucketServerPlatform) GetPlatformName() string {
+	return fmt.Sprintf("Bitbucket Server (%s)", b.config.Host)
+}
+
+// GetPlatformType returns the platform type
+func (b *BitbucketServerPlatform) GetPlatformType() PlatformType {
+	return PlatformBitbucket
+}
+
+// SupportsWebhooks returns true if the platform supports webhooks
+func (b *BitbucketServerPlatform) SupportsWebhooks() bool {
+	return true
+}
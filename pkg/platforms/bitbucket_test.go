@@ -0,0 +1,212 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestBitbucket returns a Bitbucket Cloud platform talking to a fake API
+func newTestBitbucket(t *testing.T, auth AuthConfig, handler http.Handler) *BitbucketPlatform {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	b, err := NewBitbucketPlatform(PlatformConfig{
+		Host:   server.URL,
+		Auth:   auth,
+		Mirror: MirrorConfig{Repository: "mirror", Branch: "main"},
+		Extra:  map[string]interface{}{"workspace": "ws", "cache_dir": t.TempDir()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// writeJSON answers a fake API request
+func writeJSON(t *testing.T, w http.ResponseWriter, value interface{}) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBitbucketAuthorization(t *testing.T) {
+	tests := []struct {
+		name string
+		auth AuthConfig
+		want string
+	}{
+		{"app password", AuthConfig{Username: "jane", Token: "app-password"}, "Basic amFuZTphcHAtcGFzc3dvcmQ="},
+		{"password", AuthConfig{Username: "jane", Password: "secret"}, "Basic amFuZTpzZWNyZXQ="},
+		{"access token", AuthConfig{Token: "access-token"}, "Bearer access-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			b := newTestBitbucket(t, tt.auth, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				writeJSON(t, w, map[string]string{"username": "jane"})
+			}))
+
+			if err := b.ValidateCredentials(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitbucketListRepositoriesPaginates(t *testing.T) {
+	var server string
+	b := newTestBitbucket(t, AuthConfig{Token: "token"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/ws" {
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.URL.Query().Get("page") {
+		case "":
+			writeJSON(t, w, map[string]interface{}{
+				"values": []map[string]string{{"slug": "one", "full_name": "ws/one"}},
+				"next":   server + "/repositories/ws?pagelen=100&page=2",
+			})
+		case "2":
+			writeJSON(t, w, map[string]interface{}{
+				"values": []map[string]string{{"slug": "two", "full_name": "ws/two"}},
+			})
+		default:
+			t.Errorf("unexpected page %s", r.URL)
+		}
+	}))
+	server = b.config.Host
+
+	repos, err := b.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	if got := strings.Join(names, ","); got != "ws/one,ws/two" {
+		t.Errorf("repositories = %s, want ws/one,ws/two", got)
+	}
+}
+
+func TestBitbucketGetCommitsStopsAtOlderPages(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(hash string, date time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"hash":   hash,
+			"date":   date.Format(time.RFC3339),
+			"author": map[string]interface{}{"raw": "Jane Doe <jane@example.com>"},
+		}
+	}
+
+	var server string
+	b := newTestBitbucket(t, AuthConfig{Username: "jane", Token: "token"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			// Graph order: an older commit can sit between newer ones
+			writeJSON(t, w, map[string]interface{}{
+				"values": []interface{}{
+					commit("c3", since.Add(48*time.Hour)),
+					commit("old", since.Add(-time.Hour)),
+					commit("c2", since.Add(time.Hour)),
+				},
+				"next": server + r.URL.Path + "?page=2",
+			})
+		case "2":
+			writeJSON(t, w, map[string]interface{}{
+				"values": []interface{}{commit("c1", since), commit("older", since.Add(-48*time.Hour))},
+				"next":   server + r.URL.Path + "?page=3",
+			})
+		case "3":
+			writeJSON(t, w, map[string]interface{}{
+				"values": []interface{}{commit("oldest", since.Add(-72*time.Hour))},
+				"next":   server + r.URL.Path + "?page=4",
+			})
+		default:
+			t.Errorf("read past a page without recent commits: %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	server = b.config.Host
+	b.identities = Identities{Emails: []string{"jane@example.com"}}
+
+	commits, err := b.GetCommits(context.Background(), Repository{FullName: "ws/repo"}, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashes []string
+	for _, commit := range commits {
+		hashes = append(hashes, commit.SHA)
+	}
+	if got := strings.Join(hashes, ","); got != "c3,c2,c1" {
+		t.Errorf("commits = %s, want c3,c2,c1", got)
+	}
+	if commits[0].Author.Email != "jane@example.com" {
+		t.Errorf("author email = %q, want jane@example.com", commits[0].Author.Email)
+	}
+}
+
+func TestBitbucketMirrorCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote := newBareRepository(t)
+	b := newTestBitbucket(t, AuthConfig{Username: "jane", Token: "token"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/ws/mirror" {
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{
+			"full_name": "ws/mirror",
+			"links": map[string]interface{}{
+				"clone": []map[string]string{{"name": "https", "href": remote}},
+			},
+		})
+	}))
+	b.config.Extra["name"] = "Jane Doe"
+	b.config.Extra["email"] = "jane@example.com"
+
+	zone := time.FixedZone("", 2*60*60)
+	commits := []Commit{
+		{SHA: "a", Message: "Development work", Date: time.Date(2024, 3, 1, 9, 30, 0, 0, zone)},
+		{SHA: "b", Message: "Development work", Date: time.Date(2024, 3, 2, 17, 5, 0, 0, zone)},
+	}
+
+	mirrored, err := b.MirrorCommits(context.Background(), commits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mirrored) != 2 || mirrored[0].SourceSHA != "a" || mirrored[1].SourceSHA != "b" {
+		t.Fatalf("mirrored = %+v, want a and b", mirrored)
+	}
+
+	want := []string{
+		"2024-03-02T17:05:00+02:00 2024-03-02T17:05:00+02:00 Jane Doe <jane@example.com>",
+		"2024-03-01T09:30:00+02:00 2024-03-01T09:30:00+02:00 Jane Doe <jane@example.com>",
+	}
+	if got := gitLog(t, remote, "main", "%aI %cI %an <%ae>"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("mirror history:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
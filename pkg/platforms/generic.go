@@ -0,0 +1,423 @@
+package platforms
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GenericGitPlatform implements GitPlatform for plain git repositories, read
+// from disk and pushed to any remote with the git command line without any
+// hosting API
+type GenericGitPlatform struct {
+	*identityFilter
+	config PlatformConfig
+}
+
+// NewGenericGitPlatform creates a new generic git platform instance. Each
+// configured repository is a working copy or bare repository, or a directory
+// scanned recursively for them. Commits are limited to the configured
+// identities (names or emails), defaulting to the auth username or the global
+// git user.email.
+func NewGenericGitPlatform(config PlatformConfig) (*GenericGitPlatform, error) {
+	return &GenericGitPlatform{
+		identityFilter: newIdentityFilter(config),
+		config:         config,
+	}, nil
+}
+
+// Connect stores the auth configuration (no connection is needed)
+func (g *GenericGitPlatform) Connect(ctx context.Context, config AuthConfig) error {
+	g.config.Auth = config
+	return nil
+}
+
+// ValidateCredentials checks that git is installed and the configured paths exist
+func (g *GenericGitPlatform) ValidateCredentials(ctx context.Context) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed: %w", err)
+	}
+
+	for _, path := range g.config.Repos {
+		if _, err := os.Stat(expandPath(path)); err != nil {
+			return fmt.Errorf("invalid repository path: %w", err)
+		}
+	}
+	return nil
+}
+
+// Disconnect closes any connections (no-op for local repositories)
+func (g *GenericGitPlatform) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+// ListRepositories returns the configured repositories, expanding directories
+// that aren't repositories themselves into the repositories below them
+func (g *GenericGitPlatform) ListRepositories(ctx context.Context) ([]Repository, error) {
+	var allRepos []Repository
+	seen := make(map[string]bool)
+
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+
+		name := strings.TrimSuffix(filepath.Base(path), ".git")
+		allRepos = append(allRepos, Repository{
+			ID:       path,
+			Name:     name,
+			FullName: path,
+			URL:      path,
+			CloneURL: path,
+			Private:  true,
+			Platform: "generic",
+		})
+	}
+
+	for _, configured := range g.config.Repos {
+		root, err := filepath.Abs(expandPath(configured))
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository path %s: %w", configured, err)
+		}
+
+		if isGitRepository(root) {
+			add(root)
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable directories below the root are skipped
+				if path != root && d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+
+			if isGitRepository(path) {
+				add(path)
+				return fs.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", configured, err)
+		}
+	}
+
+	return allRepos, nil
+}
+
+// isGitRepository reports whether dir is a working copy or a bare repository
+func isGitRepository(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+
+	for _, entry := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, entry)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCommits retrieves commits by the configured identities reachable from any
+// ref of a repository since a specific date
+func (g *GenericGitPlatform) GetCommits(ctx context.Context, repo Repository, since time.Time) ([]Commit, error) {
+	var allCommits []Commit
+
+	path := repo.ID
+	if path == "" {
+		path = repo.FullName
+	}
+	path = expandPath(path)
+
+	// Fields are separated by US and records by RS, which don't occur in
+	// names or messages. Stash entries are reachable from refs/stash but
+	// aren't real work.
+	logCtx, cancel := withTimeout(ctx, g.config.Timeout)
+	defer cancel()
+	out, err := runGit(logCtx, path, "log", "--exclude=refs/stash", "--all",
+		"--since="+since.Format(time.RFC3339),
+		"--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%ce%x1f%cI%x1f%B%x1e",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	for _, record := range strings.Split(string(out), "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x1f", 8)
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("failed to get commits: unexpected git log output")
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commits: invalid date %q: %w", fields[3], err)
+		}
+		committed, err := time.Parse(time.RFC3339, fields[6])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commits: invalid date %q: %w", fields[6], err)
+		}
+
+		allCommits = append(allCommits, Commit{
+			SHA:     fields[0],
+			Message: strings.TrimRight(fields[7], "\n"),
+			Author: Author{
+				Name:  fields[1],
+				Email: fields[2],
+			},
+			Committer: Author{
+				Name:  fields[4],
+				Email: fields[5],
+			},
+			Date:      date,
+			Committed: committed,
+			Repo:      repo.FullName,
+			Platform:  "generic",
+		})
+	}
+
+	return g.keep(allCommits)
+}
+
+// GetCommitCount returns the number of commits since a specific date
+func (g *GenericGitPlatform) GetCommitCount(ctx context.Context, repo Repository, since time.Time) (int, error) {
+	commits, err := g.GetCommits(ctx, repo, since)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits), nil
+}
+
+// InitializeMirror makes sure the mirror remote exists. Remotes on local
+// paths are created as bare repositories; any other remote must already
+// exist, as there is no API to create it.
+func (g *GenericGitPlatform) InitializeMirror(ctx context.Context, name string, visibility string) error {
+	remote, err := g.remoteURL(name)
+	if err != nil {
+		return err
+	}
+
+	if path, ok := localPath(remote); ok {
+		if _, err := os.Stat(path); err == nil {
+			return nil // Repo already exists, that's okay
+		}
+		initCtx, cancel := withTimeout(ctx, g.config.Timeout)
+		defer cancel()
+		if _, err := runGit(initCtx, ".", "init", "--quiet", "--bare", "--initial-branch="+g.mirrorBranch(), path); err != nil {
+			return fmt.Errorf("failed to create mirror repository: %w", err)
+		}
+		return nil
+	}
+
+	m, err := g.mirror(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := m.git(ctx, m.env, "", "ls-remote", "--heads", remote); err != nil {
+		return fmt.Errorf("mirror repository %s is not reachable - create it on the host first: %w", remote, err)
+	}
+	return nil
+}
+
+// MirrorCommits appends empty commits with preserved author and committer
+// dates to the mirror branch and pushes them in one go
+func (g *GenericGitPlatform) MirrorCommits(ctx context.Context, commits []Commit) ([]MirroredCommit, error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	m, err := g.mirror(ctx, g.config.Mirror.Repository)
+	if err != nil {
+		return nil, err
+	}
+	return m.commit(ctx, commits)
+}
+
+// ListMirrorCommits returns the commits already on the mirror branch since a specific date
+func (g *GenericGitPlatform) ListMirrorCommits(ctx context.Context, since time.Time) ([]Commit, error) {
+	m, err := g.mirror(ctx, g.config.Mirror.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := m.list(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mirror commits: %w", err)
+	}
+
+	for i := range commits {
+		commits[i].Repo = m.remote
+		commits[i].Platform = "generic"
+	}
+	return commits, nil
+}
+
+// GetMirrorStatus returns the status of the mirror repository
+func (g *GenericGitPlatform) GetMirrorStatus(ctx context.Context) (MirrorStatus, error) {
+	m, err := g.mirror(ctx, g.config.Mirror.Repository)
+	if err != nil {
+		return MirrorStatus{}, err
+	}
+
+	if err := m.sync(ctx); err != nil {
+		return MirrorStatus{}, fmt.Errorf("failed to get mirror repository: %w", err)
+	}
+	head, err := m.head(ctx)
+	if err != nil {
+		return MirrorStatus{}, fmt.Errorf("failed to get latest commit: %w", err)
+	}
+
+	return MirrorStatus{
+		Repository:    m.remote,
+		LastSync:      time.Now(), // This would need to be tracked separately
+		LastCommitSHA: head,
+		Status:        "active",
+	}, nil
+}
+
+// mirror returns the local mirror of a mirror repository's branch
+func (g *GenericGitPlatform) mirror(ctx context.Context, name string) (*gitMirror, error) {
+	remote, err := g.remoteURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	authorName, authorEmail := mirrorIdentity(ctx, g.config)
+	return newGitMirror(remote, g.mirrorBranch(), mirrorCacheDir(g.config.Extra), g.config.Auth, authorName, authorEmail, g.config.Timeout), nil
+}
+
+// remoteURL returns the remote of a mirror repository: a full URL or path
+// as-is, or a name joined to the configured host
+func (g *GenericGitPlatform) remoteURL(name string) (string, error) {
+	if strings.Contains(name, "://") || filepath.IsAbs(name) || strings.HasPrefix(name, "~") ||
+		strings.HasPrefix(name, ".") || strings.Contains(name, "@") {
+		return expandPath(name), nil
+	}
+
+	if g.config.Host == "" {
+		return "", fmt.Errorf("no remote for mirror repository %s - set host or use a full URL as mirror.repository", name)
+	}
+
+	if !strings.HasSuffix(name, ".git") {
+		name += ".git"
+	}
+	return strings.TrimSuffix(expandPath(g.config.Host), "/") + "/" + name, nil
+}
+
+// localPath returns the file system path of a remote on the local machine
+func localPath(remote string) (string, bool) {
+	if strings.HasPrefix(remote, "file://") {
+		return strings.TrimPrefix(remote, "file://"), true
+	}
+	if strings.Contains(remote, "://") || strings.Contains(remote, "@") {
+		return "", false
+	}
+	return remote, true
+}
+
+// mirrorIdentity returns the author of mirror commits pushed with git:
+// extra.name and extra.email, falling back to the global git identity and
+// then the auth username
+func mirrorIdentity(ctx context.Context, config PlatformConfig) (string, string) {
+	name := extraString(config.Extra, "name")
+	email := extraString(config.Extra, "email")
+
+	if name == "" {
+		if out, err := runGit(ctx, ".", "config", "--global", "user.name"); err == nil {
+			name = strings.TrimSpace(string(out))
+		}
+	}
+	if email == "" {
+		if out, err := runGit(ctx, ".", "config", "--global", "user.email"); err == nil {
+			email = strings.TrimSpace(string(out))
+		}
+	}
+
+	if name == "" {
+		name = config.Auth.Username
+	}
+	if email == "" {
+		email = config.Auth.Username + "@localhost"
+	}
+	return name, email
+}
+
+// mirrorBranch returns the configured mirror branch
+func (g *GenericGitPlatform) mirrorBranch() string {
+	if g.config.Mirror.Branch != "" {
+		return g.config.Mirror.Branch
+	}
+	return "main"
+}
+
+// runGit runs a git command in dir and returns its standard output
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// withTimeout limits ctx to timeout, when one is set
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// expandPath expands a leading ~ to the home directory
+func expandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// GetPlatformName returns the platform name
+func (g *GenericGitPlatform) GetPlatformName() string {
+	return "Git"
+}
+
+// GetPlatformType returns the platform type
+func (g *GenericGitPlatform) GetPlatformType() PlatformType {
+	return PlatformGenericGit
+}
+
+// SupportsWebhooks returns false: there is no server to send them
+func (g *GenericGitPlatform) SupportsWebhooks() bool {
+	return false
+}
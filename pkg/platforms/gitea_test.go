@@ -0,0 +1,157 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeGiteaMirror is a mirror repository behind a fake contents API. It
+// rejects writes that a real server would: creating a file that exists,
+// updating one with a stale SHA, or writing to a missing branch.
+type fakeGiteaMirror struct {
+	t             *testing.T
+	empty         bool
+	defaultBranch string
+	files         map[string]string // .activity blob SHA per branch
+	writes        []map[string]interface{}
+}
+
+func (f *fakeGiteaMirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const repo = "/api/v1/repos/jane/mirror"
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == repo:
+		writeJSON(f.t, w, map[string]interface{}{"full_name": "jane/mirror", "empty": f.empty, "default_branch": f.defaultBranch})
+	case r.Method == http.MethodGet && r.URL.Path == repo+"/branches/main":
+		if _, ok := f.files["main"]; !ok {
+			http.Error(w, `{"message":"branch does not exist"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(f.t, w, map[string]string{"name": "main"})
+	case r.Method == http.MethodGet && r.URL.Path == repo+"/contents/.activity":
+		sha := f.files[r.URL.Query().Get("ref")]
+		if sha == "" {
+			http.Error(w, `{"message":"file does not exist"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(f.t, w, map[string]string{"sha": sha})
+	case (r.Method == http.MethodPost || r.Method == http.MethodPut) && r.URL.Path == repo+"/contents/.activity":
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.t.Error(err)
+			return
+		}
+		body["method"] = r.Method
+		f.writes = append(f.writes, body)
+
+		branch, _ := body["branch"].(string)
+		sha, exists := f.files[branch]
+		if !exists && !(f.empty && len(f.files) == 0) {
+			http.Error(w, `{"message":"branch does not exist"}`, http.StatusNotFound)
+			return
+		}
+		if (r.Method == http.MethodPost) != (sha == "") || (r.Method == http.MethodPut && body["sha"] != sha) {
+			http.Error(w, `{"message":"sha does not match"}`, http.StatusUnprocessableEntity)
+			return
+		}
+
+		if newBranch, ok := body["new_branch"].(string); ok {
+			branch = newBranch
+		}
+		f.files[branch] = fmt.Sprintf("blob-%d", len(f.writes))
+		writeJSON(f.t, w, map[string]interface{}{
+			"content": map[string]string{"sha": f.files[branch]},
+			"commit":  map[string]string{"sha": fmt.Sprintf("commit-%d", len(f.writes))},
+		})
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusForbidden)
+	}
+}
+
+func TestGiteaMirrorCommits(t *testing.T) {
+	tests := []struct {
+		name   string
+		mirror *fakeGiteaMirror
+		want   []string // method, base branch and new branch of each write
+	}{
+		{
+			name:   "existing branch",
+			mirror: &fakeGiteaMirror{defaultBranch: "main", files: map[string]string{"main": ""}},
+			want:   []string{"POST main ", "PUT main "},
+		},
+		{
+			name:   "existing activity file",
+			mirror: &fakeGiteaMirror{defaultBranch: "main", files: map[string]string{"main": "blob-0"}},
+			want:   []string{"PUT main ", "PUT main "},
+		},
+		{
+			name:   "missing branch",
+			mirror: &fakeGiteaMirror{defaultBranch: "master", files: map[string]string{"master": "blob-0"}},
+			want:   []string{"PUT master main", "PUT main "},
+		},
+		{
+			name:   "empty repository",
+			mirror: &fakeGiteaMirror{empty: true, files: map[string]string{}},
+			want:   []string{"POST main ", "PUT main "},
+		},
+	}
+
+	zone := time.FixedZone("", 2*60*60)
+	commits := []Commit{
+		{SHA: "a", Message: "Development work", Date: time.Date(2024, 3, 1, 10, 0, 0, 0, zone)},
+		{SHA: "b", Message: "Development work", Date: time.Date(2024, 3, 1, 23, 59, 59, 0, zone)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mirror.t = t
+			server := httptest.NewServer(tt.mirror)
+			defer server.Close()
+
+			g, err := NewGiteaPlatform(PlatformConfig{
+				Platform: PlatformGitea,
+				Host:     server.URL,
+				Auth:     AuthConfig{Username: "jane", Token: "token"},
+				Mirror:   MirrorConfig{Repository: "mirror", Branch: "main"},
+				Extra:    map[string]interface{}{"email": "jane@example.com"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mirrored, err := g.MirrorCommits(context.Background(), commits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(mirrored) != 2 || mirrored[0].MirrorSHA != "commit-1" || mirrored[1].MirrorSHA != "commit-2" {
+				t.Errorf("mirrored %+v", mirrored)
+			}
+
+			if len(tt.mirror.writes) != len(tt.want) {
+				t.Fatalf("%d writes, want %d", len(tt.mirror.writes), len(tt.want))
+			}
+			for i, write := range tt.mirror.writes {
+				newBranch, _ := write["new_branch"].(string)
+				if got := fmt.Sprintf("%s %s %s", write["method"], write["branch"], newBranch); got != tt.want[i] {
+					t.Errorf("write %d = %q, want %q", i+1, got, tt.want[i])
+				}
+
+				date := commits[i].Date.Format(time.RFC3339)
+				dates, _ := write["dates"].(map[string]interface{})
+				if dates["author"] != date || dates["committer"] != date {
+					t.Errorf("write %d dated %v, want %s", i+1, dates, date)
+				}
+				author, _ := write["author"].(map[string]interface{})
+				if author["name"] != "jane" || author["email"] != "jane@example.com" {
+					t.Errorf("write %d by %v", i+1, author)
+				}
+			}
+		})
+	}
+}
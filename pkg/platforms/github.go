@@ -2,7 +2,14 @@ package platforms
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,27 +19,17 @@ import (
 
 // GitHubPlatform implements GitPlatform for GitHub
 type GitHubPlatform struct {
+	*identityFilter
 	client *github.Client
-	ctx    context.Context
 	config PlatformConfig
 	owner  string
 }
 
-// NewGitHubPlatform creates a new GitHub platform instance
+// NewGitHubPlatform creates a new GitHub platform instance. Commits are
+// limited to the configured identities, defaulting to the auth username.
 func NewGitHubPlatform(config PlatformConfig) (*GitHubPlatform, error) {
-	ctx := context.Background()
-
-	// Set up authentication
-	var client *github.Client
-	if config.Auth.Token != "" {
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: config.Auth.Token},
-		)
-		tc := oauth2.NewClient(ctx, ts)
-		client = github.NewClient(tc)
-	} else {
-		client = github.NewClient(nil) // Public access only
-	}
+	// Set up authentication; without a token only public access works
+	client := github.NewClient(githubHTTPClient(config.Auth.Token, config.RateLimitWait, config.Timeout))
 
 	// For GitHub Enterprise, set base URL
 	if config.Host != "" && config.Host != "github.com" {
@@ -47,46 +44,56 @@ func NewGitHubPlatform(config PlatformConfig) (*GitHubPlatform, error) {
 	}
 
 	return &GitHubPlatform{
-		client: client,
-		ctx:    ctx,
-		config: config,
-		owner:  config.Auth.Username,
+		identityFilter: newIdentityFilter(config),
+		client:         client,
+		config:         config,
+		owner:          config.Auth.Username,
 	}, nil
 }
 
 // Connect establishes connection to GitHub
-func (g *GitHubPlatform) Connect(config AuthConfig) error {
+func (g *GitHubPlatform) Connect(ctx context.Context, config AuthConfig) error {
 	g.config.Auth = config
 	g.owner = config.Username
 
 	// Recreate client with new config
 	if config.Token != "" {
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: config.Token},
-		)
-		tc := oauth2.NewClient(g.ctx, ts)
-		g.client = github.NewClient(tc)
+		g.client = github.NewClient(githubHTTPClient(config.Token, g.config.RateLimitWait, g.config.Timeout))
 	}
 
 	return nil
 }
 
+// githubHTTPClient returns an HTTP client that authenticates with token, if
+// any, waits out rate limits for up to rateLimitWait and limits each request
+// to timeout
+func githubHTTPClient(token string, rateLimitWait, timeout time.Duration) *http.Client {
+	var transport http.RoundTripper = newRateLimitTransport(nil, rateLimitWait, timeout)
+	if token != "" {
+		transport = &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+			Base:   transport,
+		}
+	}
+	return &http.Client{Transport: transport}
+}
+
 // ValidateCredentials validates the GitHub credentials
-func (g *GitHubPlatform) ValidateCredentials() error {
-	_, _, err := g.client.Users.Get(g.ctx, "")
+func (g *GitHubPlatform) ValidateCredentials(ctx context.Context) error {
+	_, _, err := g.client.Users.Get(ctx, "")
 	if err != nil {
-		return fmt.Errorf("invalid GitHub credentials: %w", err)
+		return fmt.Errorf("invalid GitHub credentials: %w", githubError(err))
 	}
 	return nil
 }
 
 // Disconnect closes any connections (no-op for GitHub API)
-func (g *GitHubPlatform) Disconnect() error {
+func (g *GitHubPlatform) Disconnect(ctx context.Context) error {
 	return nil
 }
 
 // ListRepositories returns all repositories for the authenticated user
-func (g *GitHubPlatform) ListRepositories() ([]Repository, error) {
+func (g *GitHubPlatform) ListRepositories(ctx context.Context) ([]Repository, error) {
 	var allRepos []Repository
 
 	opt := &github.RepositoryListOptions{
@@ -94,9 +101,9 @@ func (g *GitHubPlatform) ListRepositories() ([]Repository, error) {
 	}
 
 	for {
-		repos, resp, err := g.client.Repositories.List(g.ctx, g.owner, opt)
+		repos, resp, err := g.client.Repositories.List(ctx, g.owner, opt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories: %w", err)
+			return nil, fmt.Errorf("failed to list repositories: %w", githubError(err))
 		}
 
 		for _, repo := range repos {
@@ -123,31 +130,108 @@ func (g *GitHubPlatform) ListRepositories() ([]Repository, error) {
 	return allRepos, nil
 }
 
-// GetCommits retrieves commits from a repository since a specific date
-func (g *GitHubPlatform) GetCommits(repo Repository, since time.Time) ([]Commit, error) {
-	var allCommits []Commit
-
-	// Parse owner/repo from full name
+// GetCommits retrieves commits by the configured identities from a repository
+// since a specific date. The API is asked for each login and email in turn,
+// as author and as committer, so only the user's commits are downloaded, and
+// the results are matched against the identities again.
+func (g *GitHubPlatform) GetCommits(ctx context.Context, repo Repository, since time.Time) ([]Commit, error) {
+	// Parse owner/repo from full name, defaulting to the authenticated user
 	parts := strings.Split(repo.FullName, "/")
-	if len(parts) != 2 {
+	var owner, repoName string
+	switch {
+	case len(parts) == 2:
+		owner, repoName = parts[0], parts[1]
+	case len(parts) == 1 && g.owner != "":
+		owner, repoName = g.owner, parts[0]
+	default:
 		return nil, fmt.Errorf("invalid repository full name: %s", repo.FullName)
 	}
-	owner, repoName := parts[0], parts[1]
 
-	opt := &github.CommitsListOptions{
-		Since:       since,
-		ListOptions: github.ListOptions{PerPage: 100},
+	filters := []url.Values{nil}
+	if authors := g.authorFilters(); authors != nil {
+		filters = nil
+		for _, author := range authors {
+			filters = append(filters, url.Values{"author": {author}}, url.Values{"committer": {author}})
+		}
 	}
 
-	// Filter by author email if specified
-	if g.config.Auth.Username != "" {
-		opt.Author = g.config.Auth.Username
+	var allCommits []Commit
+	seen := make(map[string]bool)
+	for _, filter := range filters {
+		commits, err := g.listCommits(ctx, owner, repoName, repo, since, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if !seen[commit.SHA] {
+				seen[commit.SHA] = true
+				allCommits = append(allCommits, commit)
+			}
+		}
+	}
+
+	return g.keep(allCommits)
+}
+
+// githubLogin matches strings that can be GitHub logins
+var githubLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+
+// authorFilters returns the logins and emails the commits API can be asked
+// for, one at a time, to find the identities' commits. It returns nil when
+// every commit has to be fetched instead: when an identity is only a name,
+// which the API can't filter by, or when a mailmap may map other emails to
+// the user. A name that is also listed as a login or email, as auth.username
+// and extra.authors are, is found through that.
+func (g *GitHubPlatform) authorFilters() []string {
+	if g.mailmap != nil {
+		return nil
+	}
+
+	identities := g.identities
+	for _, name := range identities.Names {
+		if !slices.Contains(identities.Usernames, name) && !slices.Contains(identities.Emails, name) {
+			return nil
+		}
+	}
+
+	var authors []string
+	for _, values := range [][]string{identities.Usernames, identities.Emails} {
+		for _, value := range values {
+			if !strings.Contains(value, "@") && !githubLogin.MatchString(value) {
+				return nil
+			}
+			if !containsFold(authors, value) {
+				authors = append(authors, value)
+			}
+		}
+	}
+	return authors
+}
+
+// listCommits lists a repository's commits since a date, limited by filter to
+// one author or committer (a login or an email) unless it is empty. The
+// client's options have no committer filter, so the query is built here.
+func (g *GitHubPlatform) listCommits(ctx context.Context, owner, repoName string, repo Repository, since time.Time, filter url.Values) ([]Commit, error) {
+	var allCommits []Commit
+
+	query := url.Values{"per_page": {"100"}}
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339))
+	}
+	for key, values := range filter {
+		query[key] = values
 	}
 
 	for {
-		commits, resp, err := g.client.Repositories.ListCommits(g.ctx, owner, repoName, opt)
+		req, err := g.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/commits?%s", owner, repoName, query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var commits []*github.RepositoryCommit
+		resp, err := g.client.Do(ctx, req, &commits)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get commits: %w", err)
+			return nil, fmt.Errorf("failed to get commits: %w", githubError(err))
 		}
 
 		for _, commit := range commits {
@@ -159,164 +243,299 @@ func (g *GitHubPlatform) GetCommits(repo Repository, since time.Time) ([]Commit,
 				SHA:     commit.GetSHA(),
 				Message: commit.Commit.GetMessage(),
 				Author: Author{
-					Name:  commit.Commit.Author.GetName(),
-					Email: commit.Commit.Author.GetEmail(),
+					Name:     commit.Commit.Author.GetName(),
+					Email:    commit.Commit.Author.GetEmail(),
+					Username: commit.GetAuthor().GetLogin(),
 				},
 				Committer: Author{
-					Name:  commit.Commit.Committer.GetName(),
-					Email: commit.Commit.Committer.GetEmail(),
+					Name:     commit.Commit.Committer.GetName(),
+					Email:    commit.Commit.Committer.GetEmail(),
+					Username: commit.GetCommitter().GetLogin(),
 				},
-				Date:     commit.Commit.Author.GetDate().Time,
-				URL:      commit.GetHTMLURL(),
-				Repo:     repo.FullName,
-				Platform: "github",
+				Date:      commit.Commit.Author.GetDate().Time,
+				Committed: commit.Commit.Committer.GetDate().Time,
+				URL:       commit.GetHTMLURL(),
+				Repo:      repo.FullName,
+				Platform:  "github",
 			})
 		}
 
 		if resp.NextPage == 0 {
 			break
 		}
-		opt.Page = resp.NextPage
+		query.Set("page", strconv.Itoa(resp.NextPage))
 	}
 
 	return allCommits, nil
 }
 
 // GetCommitCount returns the number of commits since a specific date
-func (g *GitHubPlatform) GetCommitCount(repo Repository, since time.Time) (int, error) {
-	commits, err := g.GetCommits(repo, since)
+func (g *GitHubPlatform) GetCommitCount(ctx context.Context, repo Repository, since time.Time) (int, error) {
+	commits, err := g.GetCommits(ctx, repo, since)
 	if err != nil {
 		return 0, err
 	}
 	return len(commits), nil
 }
 
-// InitializeMirror creates a new repository for mirroring
-func (g *GitHubPlatform) InitializeMirror(name string, visibility string) error {
+// InitializeMirror creates a new repository for mirroring and starts the
+// mirror branch with a root commit
+func (g *GitHubPlatform) InitializeMirror(ctx context.Context, name string, visibility string) error {
+	owner, repoName := g.splitRepository(name)
+
 	repo := &github.Repository{
-		Name:        github.String(name),
+		Name:        github.String(repoName),
 		Description: github.String("Mirror of git activity from other platforms"),
 		Private:     github.Bool(visibility == "private"),
 	}
 
-	_, _, err := g.client.Repositories.Create(g.ctx, "", repo)
-	if err != nil {
-		// Check if repo already exists
-		if strings.Contains(err.Error(), "already exists") {
-			return nil // Repo already exists, that's okay
+	// An empty org creates the repository for the authenticated user
+	org := ""
+	if !strings.EqualFold(owner, g.owner) {
+		org = owner
+	}
+
+	// Look before creating: tokens without permission to create repositories
+	// can still write to one that exists
+	_, _, err := g.client.Repositories.Get(ctx, owner, repoName)
+	switch err = githubError(err); {
+	case err == nil:
+	case errors.Is(err, ErrRepositoryNotFound):
+		_, _, err = g.client.Repositories.Create(ctx, org, repo)
+		if err = githubError(err); err != nil && !errors.Is(err, ErrAlreadyExists) {
+			return fmt.Errorf("failed to create mirror repository: %w", err)
 		}
-		return fmt.Errorf("failed to create mirror repository: %w", err)
+	default:
+		return fmt.Errorf("failed to get mirror repository: %w", err)
 	}
 
-	return nil
+	// Created without auto-init, so the repository is empty; an existing
+	// one may not have the mirror branch yet either
+	_, err = g.ensureBranch(ctx, owner, repoName)
+	return err
 }
 
-// MirrorCommits creates mirror commits with preserved timestamps
-func (g *GitHubPlatform) MirrorCommits(commits []Commit) error {
-	if len(commits) == 0 {
-		return nil
+// ensureBranch returns the mirror branch reference, starting the branch with
+// a root commit first if the repository is empty or doesn't have it yet, so
+// commits can be chained onto it
+func (g *GitHubPlatform) ensureBranch(ctx context.Context, owner, repoName string) (*github.Reference, error) {
+	ref, resp, err := g.client.Git.GetRef(ctx, owner, repoName, "refs/heads/"+g.mirrorBranch())
+	if err == nil {
+		return ref, nil
 	}
-
-	// Get the mirror repository
-	mirrorRepo := g.config.Mirror.Repository
-	parts := strings.Split(mirrorRepo, "/")
-	var owner, repoName string
-
-	if len(parts) == 2 {
-		owner, repoName = parts[0], parts[1]
-	} else {
-		owner = g.owner
-		repoName = mirrorRepo
+	// An empty repository answers 409, a missing branch 404
+	if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusConflict) {
+		return nil, fmt.Errorf("failed to get mirror branch: %w", githubError(err))
 	}
 
-	// For each commit, create an empty commit with preserved timestamp
-	for _, commit := range commits {
-		// Create a simple commit message
-		message := fmt.Sprintf("Development work - %s", commit.Date.Format("2006-01-02"))
+	ref, err = g.createRootCommit(ctx, owner, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mirror branch %s: %w", g.mirrorBranch(), githubError(err))
+	}
+	return ref, nil
+}
 
-		// Get the current HEAD to create a new commit
-		ref, _, err := g.client.Git.GetRef(g.ctx, owner, repoName, "refs/heads/main")
-		if err != nil {
-			// Try master branch if main doesn't exist
-			ref, _, err = g.client.Git.GetRef(g.ctx, owner, repoName, "refs/heads/master")
+// mirrorRootContent is the content of the file holding the mirror's root commit
+const mirrorRootContent = "Mirror of git activity from other platforms\n"
+
+// createRootCommit starts the mirror branch with an orphan commit and returns
+// the new branch reference. The Git database API refuses to work on an empty
+// repository, where the first commit is created through the contents API
+// instead.
+func (g *GitHubPlatform) createRootCommit(ctx context.Context, owner, repoName string) (*github.Reference, error) {
+	branch := g.mirrorBranch()
+	message := "Initialize activity mirror"
+
+	tree, resp, err := g.client.Git.CreateTree(ctx, owner, repoName, "", []*github.TreeEntry{{
+		Path:    github.String(".activity"),
+		Mode:    github.String("100644"),
+		Type:    github.String("blob"),
+		Content: github.String(mirrorRootContent),
+	}})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusConflict {
+			created, _, err := g.client.Repositories.CreateFile(ctx, owner, repoName, ".activity", &github.RepositoryContentFileOptions{
+				Message: github.String(message),
+				Content: []byte(mirrorRootContent),
+				Branch:  github.String(branch),
+			})
 			if err != nil {
-				return fmt.Errorf("failed to get repository head: %w", err)
+				return nil, err
 			}
+			return &github.Reference{
+				Ref:    github.String("refs/heads/" + branch),
+				Object: &github.GitObject{SHA: created.Commit.SHA},
+			}, nil
 		}
+		return nil, err
+	}
 
-		// Create a tree (empty change)
-		baseTree, _, err := g.client.Git.GetTree(g.ctx, owner, repoName, ref.Object.GetSHA(), false)
+	root, _, err := g.client.Git.CreateCommit(ctx, owner, repoName, &github.Commit{
+		Message: github.String(message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+	}, &github.CreateCommitOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ref, _, err := g.client.Git.CreateRef(ctx, owner, repoName, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: root.SHA},
+	})
+	return ref, err
+}
+
+// refUpdateAttempts bounds how often a batch is rebuilt when the mirror
+// branch moves while it is being written
+const refUpdateAttempts = 3
+
+// MirrorCommits creates mirror commits with preserved timestamps. The branch
+// head is resolved once, the commits are chained onto it in chronological
+// order, and the branch is moved once for the whole batch. If a commit fails,
+// the ones created before it are still published and returned.
+func (g *GitHubPlatform) MirrorCommits(ctx context.Context, commits []Commit) ([]MirroredCommit, error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	owner, repoName := g.mirrorRepository()
+
+	ordered := make([]Commit, len(commits))
+	copy(ordered, commits)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Date.Before(ordered[j].Date)
+	})
+
+	for attempt := 1; ; attempt++ {
+		ref, err := g.ensureBranch(ctx, owner, repoName)
 		if err != nil {
-			return fmt.Errorf("failed to get base tree: %w", err)
+			return nil, err
 		}
 
-		// Create commit parameters
-		author := &github.CommitAuthor{
-			Date:  &github.Timestamp{Time: commit.Date},
-			Name:  github.String(g.config.Auth.Username),
-			Email: github.String(g.config.Auth.Username + "@users.noreply.github.com"),
+		mirrored, head, chainErr := g.chainCommits(ctx, owner, repoName, ref.Object.GetSHA(), ordered)
+		if len(mirrored) == 0 {
+			return nil, chainErr
 		}
 
-		committer := &github.CommitAuthor{
-			Date:  &github.Timestamp{Time: commit.Date},
-			Name:  github.String(g.config.Auth.Username),
-			Email: github.String(g.config.Auth.Username + "@users.noreply.github.com"),
+		// Move the branch once; without force this fails if it moved meanwhile
+		ref.Object.SHA = github.String(head)
+		_, resp, err := g.client.Git.UpdateRef(ctx, owner, repoName, ref, false)
+		if err == nil {
+			return mirrored, chainErr
 		}
 
-		parents := []*github.Commit{
-			{SHA: ref.Object.SHA},
+		if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity && attempt < refUpdateAttempts {
+			// Someone else pushed: rebuild the chain on the new head. The
+			// commits created so far stay unreferenced and are collected.
+			continue
 		}
+		return nil, fmt.Errorf("failed to update ref: %w", githubError(err))
+	}
+}
+
+// chainCommits creates a commit per mirrored commit, each the parent of the
+// next, starting from parent and keeping its tree. It returns the commits
+// created and the last one's SHA, with the error that stopped it, if any.
+func (g *GitHubPlatform) chainCommits(ctx context.Context, owner, repoName, parent string, commits []Commit) ([]MirroredCommit, string, error) {
+	var mirrored []MirroredCommit
 
-		tree := &github.Tree{SHA: baseTree.SHA}
+	head, _, err := g.client.Git.GetCommit(ctx, owner, repoName, parent)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get base tree: %w", githubError(err))
+	}
+	tree := &github.Tree{SHA: head.GetTree().SHA}
+
+	for _, commit := range commits {
+		signature := &github.CommitAuthor{
+			Date:  &github.Timestamp{Time: commit.Date},
+			Name:  github.String(g.config.Auth.Username),
+			Email: github.String(g.config.Auth.Username + "@users.noreply.github.com"),
+		}
 
-		// Create the commit
-		createdCommit, _, err := g.client.Git.CreateCommit(g.ctx, owner, repoName, &github.Commit{
-			Message:   github.String(message),
+		createdCommit, _, err := g.client.Git.CreateCommit(ctx, owner, repoName, &github.Commit{
+			Message:   github.String(commit.Message),
 			Tree:      tree,
-			Parents:   parents,
-			Author:    author,
-			Committer: committer,
+			Parents:   []*github.Commit{{SHA: github.String(parent)}},
+			Author:    signature,
+			Committer: signature,
 		}, &github.CreateCommitOptions{})
 		if err != nil {
-			return fmt.Errorf("failed to create mirror commit: %w", err)
+			return mirrored, parent, fmt.Errorf("failed to create mirror commit: %w", githubError(err))
 		}
+		parent = createdCommit.GetSHA()
+
+		mirrored = append(mirrored, MirroredCommit{
+			SourceSHA: commit.SHA,
+			MirrorSHA: parent,
+		})
+	}
+
+	return mirrored, parent, nil
+}
+
+// ListMirrorCommits returns the commits already on the mirror branch since a specific date
+func (g *GitHubPlatform) ListMirrorCommits(ctx context.Context, since time.Time) ([]Commit, error) {
+	var allCommits []Commit
 
-		// Update the reference
-		ref.Object.SHA = createdCommit.SHA
-		_, _, err = g.client.Git.UpdateRef(g.ctx, owner, repoName, ref, false)
+	owner, repoName := g.mirrorRepository()
+
+	opt := &github.CommitsListOptions{
+		SHA:         g.mirrorBranch(),
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		commits, resp, err := g.client.Repositories.ListCommits(ctx, owner, repoName, opt)
 		if err != nil {
-			return fmt.Errorf("failed to update ref: %w", err)
+			// An empty repository or missing branch has no history yet
+			if resp != nil && (resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusNotFound) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list mirror commits: %w", githubError(err))
+		}
+
+		for _, commit := range commits {
+			if commit.Commit == nil || commit.Commit.Author == nil {
+				continue
+			}
+
+			allCommits = append(allCommits, Commit{
+				SHA:      commit.GetSHA(),
+				Message:  commit.Commit.GetMessage(),
+				Date:     commit.Commit.Author.GetDate().Time,
+				URL:      commit.GetHTMLURL(),
+				Repo:     owner + "/" + repoName,
+				Platform: "github",
+			})
 		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
 	}
 
-	return nil
+	return allCommits, nil
 }
 
 // GetMirrorStatus returns the status of the mirror repository
-func (g *GitHubPlatform) GetMirrorStatus() (MirrorStatus, error) {
-	mirrorRepo := g.config.Mirror.Repository
-	parts := strings.Split(mirrorRepo, "/")
-	var owner, repoName string
-
-	if len(parts) == 2 {
-		owner, repoName = parts[0], parts[1]
-	} else {
-		owner = g.owner
-		repoName = mirrorRepo
-	}
+func (g *GitHubPlatform) GetMirrorStatus(ctx context.Context) (MirrorStatus, error) {
+	owner, repoName := g.mirrorRepository()
 
 	// Get repository info
-	repo, _, err := g.client.Repositories.Get(g.ctx, owner, repoName)
+	repo, _, err := g.client.Repositories.Get(ctx, owner, repoName)
 	if err != nil {
-		return MirrorStatus{}, fmt.Errorf("failed to get mirror repository: %w", err)
+		return MirrorStatus{}, fmt.Errorf("failed to get mirror repository: %w", githubError(err))
 	}
 
 	// Get latest commit
-	commits, _, err := g.client.Repositories.ListCommits(g.ctx, owner, repoName, &github.CommitsListOptions{
+	commits, _, err := g.client.Repositories.ListCommits(ctx, owner, repoName, &github.CommitsListOptions{
+		SHA:         g.mirrorBranch(),
 		ListOptions: github.ListOptions{PerPage: 1},
 	})
 	if err != nil {
-		return MirrorStatus{}, fmt.Errorf("failed to get latest commit: %w", err)
+		return MirrorStatus{}, fmt.Errorf("failed to get latest commit: %w", githubError(err))
 	}
 
 	status := MirrorStatus{
@@ -333,6 +552,56 @@ func (g *GitHubPlatform) GetMirrorStatus() (MirrorStatus, error) {
 	return status, nil
 }
 
+// mirrorRepository splits the configured mirror repository into owner and name,
+// defaulting to the authenticated user as owner
+func (g *GitHubPlatform) mirrorRepository() (string, string) {
+	return g.splitRepository(g.config.Mirror.Repository)
+}
+
+// splitRepository splits a repository name into owner and name, defaulting
+// to the authenticated user as owner
+func (g *GitHubPlatform) splitRepository(name string) (string, string) {
+	parts := strings.Split(name, "/")
+
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return g.owner, name
+}
+
+// mirrorBranch returns the configured mirror branch
+func (g *GitHubPlatform) mirrorBranch() string {
+	if g.config.Mirror.Branch != "" {
+		return g.config.Mirror.Branch
+	}
+	return "main"
+}
+
+// githubError tags a GitHub API error with the common platform error it
+// stands for
+func githubError(err error) error {
+	var rateLimit *github.RateLimitError
+	var abuse *github.AbuseRateLimitError
+	var response *github.ErrorResponse
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rateLimit), errors.As(err, &abuse):
+		return &platformError{kind: ErrRateLimit, err: err}
+	case errors.As(err, &response) && response.Response != nil:
+		// Details such as "name already exists on this account" are in Errors
+		message := response.Message
+		for _, detail := range response.Errors {
+			message += " " + detail.Message
+		}
+		if kind := errorForStatus(response.Response.StatusCode, message); kind != nil {
+			return &platformError{kind: kind, err: err}
+		}
+	}
+	return err
+}
+
 // GetPlatformName returns the platform name
 func (g *GitHubPlatform) GetPlatformName() string {
 	if g.config.Host != "" && g.config.Host != "github.com" {
@@ -0,0 +1,211 @@
+package platforms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestGitHub returns a GitHub platform talking to a fake API
+func newTestGitHub(t *testing.T, config PlatformConfig, handler http.Handler) *GitHubPlatform {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	g, err := NewGitHubPlatform(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.client.BaseURL, err = url.Parse(server.URL + "/"); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGitHubGetCommitsFiltersByAuthor(t *testing.T) {
+	commit := func(sha, author, committer string) map[string]interface{} {
+		person := func(login string) map[string]interface{} {
+			return map[string]interface{}{"name": login, "email": login + "@example.com", "date": "2024-03-01T10:00:00Z"}
+		}
+		return map[string]interface{}{
+			"sha":       sha,
+			"commit":    map[string]interface{}{"message": "work", "author": person(author), "committer": person(committer)},
+			"author":    map[string]interface{}{"login": author},
+			"committer": map[string]interface{}{"login": committer},
+		}
+	}
+
+	t.Run("logins and emails", func(t *testing.T) {
+		var queried []string
+		g := newTestGitHub(t, PlatformConfig{
+			Auth:       AuthConfig{Username: "jane", Token: "token"},
+			Identities: Identities{Usernames: []string{"jane"}, Emails: []string{"jane@old.example.com"}},
+		}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			filter := "author=" + query.Get("author")
+			if query.Has("committer") {
+				filter = "committer=" + query.Get("committer")
+			}
+			queried = append(queried, filter)
+
+			switch filter {
+			case "author=jane":
+				writeJSON(t, w, []interface{}{commit("a", "jane", "jane"), commit("b", "jane", "web-flow")})
+			case "author=jane@old.example.com":
+				writeJSON(t, w, []interface{}{commit("b", "jane", "web-flow"), commit("c", "jane", "jane")})
+			case "committer=jane":
+				// Committed by the user only, e.g. a rebased or cherry-picked commit
+				writeJSON(t, w, []interface{}{commit("a", "jane", "jane"), commit("d", "joe", "jane")})
+			case "committer=jane@old.example.com":
+				writeJSON(t, w, []interface{}{})
+			default:
+				t.Errorf("unfiltered or unexpected request %s", r.URL)
+				writeJSON(t, w, []interface{}{})
+			}
+		}))
+
+		commits, err := g.GetCommits(context.Background(), Repository{FullName: "jane/repo"}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var shas []string
+		for _, commit := range commits {
+			shas = append(shas, commit.SHA)
+		}
+		sort.Strings(shas)
+		if got := strings.Join(shas, ","); got != "a,b,c,d" {
+			t.Errorf("commits = %s, want a,b,c,d", got)
+		}
+
+		sort.Strings(queried)
+		want := "author=jane,author=jane@old.example.com,committer=jane,committer=jane@old.example.com"
+		if got := strings.Join(queried, ","); got != want {
+			t.Errorf("queried %s, want %s", got, want)
+		}
+	})
+
+	t.Run("single-word name", func(t *testing.T) {
+		var requests int
+		g := newTestGitHub(t, PlatformConfig{
+			Auth:       AuthConfig{Username: "jane-doe", Token: "token"},
+			Identities: Identities{Names: []string{"jane"}, Usernames: []string{"jane-doe"}},
+		}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if query := r.URL.Query(); query.Has("author") || query.Has("committer") {
+				t.Errorf("filtered request %s for a name", r.URL)
+			}
+			writeJSON(t, w, []interface{}{commit("a", "jane", "jane"), commit("b", "joe", "joe")})
+		}))
+
+		commits, err := g.GetCommits(context.Background(), Repository{FullName: "jane-doe/repo"}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if requests != 1 || len(commits) != 1 || commits[0].SHA != "a" {
+			t.Errorf("%d requests returned %v, want one request returning a", requests, commits)
+		}
+	})
+}
+
+func TestGitHubAuthorFilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		identities Identities
+		mailmap    bool
+		want       string
+	}{
+		{"logins and emails", Identities{Usernames: []string{"jane"}, Emails: []string{"jane@example.com"}}, false, "jane,jane@example.com"},
+		{"name only", Identities{Names: []string{"Jane Doe"}, Usernames: []string{"jane"}}, false, ""},
+		{"single-word name", Identities{Names: []string{"Jane"}, Usernames: []string{"jane-doe"}}, false, ""},
+		{"auth username", Identities{Names: []string{"jane"}, Emails: []string{"jane"}, Usernames: []string{"jane"}}, false, "jane"},
+		{"mailmap", Identities{Usernames: []string{"jane"}}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &GitHubPlatform{identityFilter: &identityFilter{identities: tt.identities}}
+			if tt.mailmap {
+				g.mailmap = &Mailmap{}
+			}
+			if got := strings.Join(g.authorFilters(), ","); got != tt.want {
+				t.Errorf("authorFilters() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubInitializeMirrorCreatesOnlyMissingRepositories(t *testing.T) {
+	for _, exists := range []bool{true, false} {
+		var created bool
+		g := newTestGitHub(t, PlatformConfig{
+			Auth:   AuthConfig{Username: "jane", Token: "token"},
+			Mirror: MirrorConfig{Repository: "mirror", Branch: "main"},
+		}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/repos/jane/mirror":
+				if !exists && !created {
+					w.WriteHeader(http.StatusNotFound)
+					writeJSON(t, w, map[string]string{"message": "Not Found"})
+					return
+				}
+				writeJSON(t, w, map[string]string{"full_name": "jane/mirror"})
+			case r.Method == http.MethodPost && r.URL.Path == "/user/repos":
+				created = true
+				writeJSON(t, w, map[string]string{"full_name": "jane/mirror"})
+			case r.Method == http.MethodGet && r.URL.Path == "/repos/jane/mirror/git/ref/heads/main":
+				writeJSON(t, w, map[string]interface{}{"ref": "refs/heads/main", "object": map[string]string{"sha": "root"}})
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}))
+
+		if err := g.InitializeMirror(context.Background(), "mirror", "private"); err != nil {
+			t.Fatal(err)
+		}
+		if created == exists {
+			t.Errorf("repository exists: %v, created: %v", exists, created)
+		}
+	}
+}
+
+func TestGitHubMirrorCommitsReadsTheBranchOnce(t *testing.T) {
+	var refReads int
+	g := newTestGitHub(t, PlatformConfig{
+		Auth:   AuthConfig{Username: "jane", Token: "token"},
+		Mirror: MirrorConfig{Repository: "mirror", Branch: "main"},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jane/mirror/git/ref/heads/main":
+			refReads++
+			writeJSON(t, w, map[string]interface{}{"ref": "refs/heads/main", "object": map[string]string{"sha": "root"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/jane/mirror/git/commits/root":
+			writeJSON(t, w, map[string]interface{}{"sha": "root", "tree": map[string]string{"sha": "tree"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/jane/mirror/git/commits":
+			writeJSON(t, w, map[string]string{"sha": "mirrored"})
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/jane/mirror/git/refs/heads/main":
+			writeJSON(t, w, map[string]interface{}{"ref": "refs/heads/main", "object": map[string]string{"sha": "mirrored"}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+
+	mirrored, err := g.MirrorCommits(context.Background(), []Commit{{SHA: "a", Message: "work", Date: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mirrored) != 1 || mirrored[0].MirrorSHA != "mirrored" {
+		t.Errorf("mirrored = %+v", mirrored)
+	}
+	if refReads != 1 {
+		t.Errorf("read the mirror branch %d times, want 1", refReads)
+	}
+}
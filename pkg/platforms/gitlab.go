@@ -1,7 +1,10 @@
 package platforms
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -10,16 +13,17 @@ import (
 
 // GitLabPlatform implements GitPlatform for GitLab
 type GitLabPlatform struct {
-	client *gitlab.Client
-	config PlatformConfig
-	userID int
+	*identityFilter
+	client   *gitlab.Client
+	config   PlatformConfig
+	userID   int
+	username string
 }
 
-// NewGitLabPlatform creates a new GitLab platform instance
+// NewGitLabPlatform creates a new GitLab platform instance. Commits are
+// limited to the configured identities, defaulting to the authenticated
+// user's name and emails.
 func NewGitLabPlatform(config PlatformConfig) (*GitLabPlatform, error) {
-	var client *gitlab.Client
-	var err error
-
 	// Create client based on host
 	host := config.Host
 	if host == "" {
@@ -29,24 +33,20 @@ func NewGitLabPlatform(config PlatformConfig) (*GitLabPlatform, error) {
 		host = "https://" + host
 	}
 
-	if config.Auth.Token != "" {
-		client, err = gitlab.NewClient(config.Auth.Token, gitlab.WithBaseURL(host))
-	} else {
-		client, err = gitlab.NewClient("", gitlab.WithBaseURL(host))
-	}
-
+	client, err := newGitLabClient(config.Auth.Token, host, config.RateLimitWait, config.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 	}
 
 	return &GitLabPlatform{
-		client: client,
-		config: config,
+		identityFilter: &identityFilter{identities: configuredIdentities(config), mailmap: config.Mailmap},
+		client:         client,
+		config:         config,
 	}, nil
 }
 
 // Connect establishes connection to GitLab
-func (g *GitLabPlatform) Connect(config AuthConfig) error {
+func (g *GitLabPlatform) Connect(ctx context.Context, config AuthConfig) error {
 	g.config.Auth = config
 
 	// Recreate client with new config
@@ -59,33 +59,38 @@ func (g *GitLabPlatform) Connect(config AuthConfig) error {
 	}
 
 	var err error
-	if config.Token != "" {
-		g.client, err = gitlab.NewClient(config.Token, gitlab.WithBaseURL(host))
-	} else {
-		g.client, err = gitlab.NewClient("", gitlab.WithBaseURL(host))
-	}
+	g.client, err = newGitLabClient(config.Token, host, g.config.RateLimitWait, g.config.Timeout)
 
 	return err
 }
 
+// newGitLabClient creates a GitLab API client whose rate limited requests
+// are waited out by the transport for up to rateLimitWait, each request
+// limited to timeout. The client's own retries still cover server errors.
+func newGitLabClient(token, host string, rateLimitWait, timeout time.Duration) (*gitlab.Client, error) {
+	httpClient := &http.Client{Transport: newRateLimitTransport(nil, rateLimitWait, timeout)}
+	return gitlab.NewClient(token, gitlab.WithBaseURL(host), gitlab.WithHTTPClient(httpClient))
+}
+
 // ValidateCredentials validates the GitLab credentials
-func (g *GitLabPlatform) ValidateCredentials() error {
-	user, _, err := g.client.Users.CurrentUser()
+func (g *GitLabPlatform) ValidateCredentials(ctx context.Context) error {
+	user, _, err := g.client.Users.CurrentUser(gitlab.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("invalid GitLab credentials: %w", err)
+		return fmt.Errorf("invalid GitLab credentials: %w", gitlabError(err))
 	}
 
 	g.userID = user.ID
+	g.username = user.Username
 	return nil
 }
 
 // Disconnect closes any connections (no-op for GitLab API)
-func (g *GitLabPlatform) Disconnect() error {
+func (g *GitLabPlatform) Disconnect(ctx context.Context) error {
 	return nil
 }
 
 // ListRepositories returns all repositories for the authenticated user
-func (g *GitLabPlatform) ListRepositories() ([]Repository, error) {
+func (g *GitLabPlatform) ListRepositories(ctx context.Context) ([]Repository, error) {
 	var allRepos []Repository
 
 	opt := &gitlab.ListProjectsOptions{
@@ -94,9 +99,9 @@ func (g *GitLabPlatform) ListRepositories() ([]Repository, error) {
 	}
 
 	for {
-		projects, resp, err := g.client.Projects.ListProjects(opt)
+		projects, resp, err := g.client.Projects.ListProjects(opt, gitlab.WithContext(ctx))
 		if err != nil {
-			return nil, fmt.Errorf("failed to list projects: %w", err)
+			return nil, fmt.Errorf("failed to list projects: %w", gitlabError(err))
 		}
 
 		for _, project := range projects {
@@ -123,10 +128,15 @@ func (g *GitLabPlatform) ListRepositories() ([]Repository, error) {
 	return allRepos, nil
 }
 
-// GetCommits retrieves commits from a repository since a specific date
-func (g *GitLabPlatform) GetCommits(repo Repository, since time.Time) ([]Commit, error) {
+// GetCommits retrieves commits by the configured identities from a
+// repository since a specific date
+func (g *GitLabPlatform) GetCommits(ctx context.Context, repo Repository, since time.Time) ([]Commit, error) {
 	var allCommits []Commit
 
+	if err := g.resolveIdentities(ctx); err != nil {
+		return nil, err
+	}
+
 	projectID := repo.ID
 
 	opt := &gitlab.ListCommitsOptions{
@@ -134,13 +144,13 @@ func (g *GitLabPlatform) GetCommits(repo Repository, since time.Time) ([]Commit,
 		Since:       &since,
 	}
 
-	// Note: GitLab API doesn't support AuthorEmail filter in ListCommitsOptions
-	// We'll filter commits by author email after fetching if needed
+	// The API searches a single author only; identities are matched against
+	// authors and committers after fetching
 
 	for {
-		commits, resp, err := g.client.Commits.ListCommits(projectID, opt)
+		commits, resp, err := g.client.Commits.ListCommits(projectID, opt, gitlab.WithContext(ctx))
 		if err != nil {
-			return nil, fmt.Errorf("failed to get commits: %w", err)
+			return nil, fmt.Errorf("failed to get commits: %w", gitlabError(err))
 		}
 
 		for _, commit := range commits {
@@ -155,10 +165,11 @@ func (g *GitLabPlatform) GetCommits(repo Repository, since time.Time) ([]Commit,
 					Name:  commit.CommitterName,
 					Email: commit.CommitterEmail,
 				},
-				Date:     *commit.AuthoredDate,
-				URL:      commit.WebURL,
-				Repo:     repo.FullName,
-				Platform: "gitlab",
+				Date:      *commit.AuthoredDate,
+				Committed: *commit.CommittedDate,
+				URL:       commit.WebURL,
+				Repo:      repo.FullName,
+				Platform:  "gitlab",
 			})
 		}
 
@@ -168,135 +179,249 @@ func (g *GitLabPlatform) GetCommits(repo Repository, since time.Time) ([]Commit,
 		opt.Page = resp.NextPage
 	}
 
-	return allCommits, nil
+	return g.keep(allCommits)
+}
+
+// OwnCommits filters commits read elsewhere, e.g. from a push webhook
+func (g *GitLabPlatform) OwnCommits(ctx context.Context, commits []Commit) ([]Commit, error) {
+	if err := g.resolveIdentities(ctx); err != nil {
+		return nil, err
+	}
+	return g.keep(commits)
+}
+
+// resolveIdentities falls back to the authenticated user's name, emails and
+// username when no identities are configured. GitLab commits carry no
+// usernames, so it is the name and emails that match.
+func (g *GitLabPlatform) resolveIdentities(ctx context.Context) error {
+	if !g.identities.IsZero() {
+		return nil
+	}
+
+	user, _, err := g.client.Users.CurrentUser(gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", gitlabError(err))
+	}
+
+	identities := Identities{Names: []string{user.Name}, Usernames: []string{user.Username}}
+	for _, email := range []string{user.Email, user.PublicEmail} {
+		if email != "" {
+			identities.Emails = append(identities.Emails, email)
+		}
+	}
+	g.identities = identities
+	return nil
 }
 
 // GetCommitCount returns the number of commits since a specific date
-func (g *GitLabPlatform) GetCommitCount(repo Repository, since time.Time) (int, error) {
-	commits, err := g.GetCommits(repo, since)
+func (g *GitLabPlatform) GetCommitCount(ctx context.Context, repo Repository, since time.Time) (int, error) {
+	commits, err := g.GetCommits(ctx, repo, since)
 	if err != nil {
 		return 0, err
 	}
 	return len(commits), nil
 }
 
-// InitializeMirror creates a new project for mirroring
-func (g *GitLabPlatform) InitializeMirror(name string, visibility string) error {
+// InitializeMirror creates a new project for mirroring, initialized with a
+// root commit on the mirror branch. A name with a namespace, e.g.
+// group/mirror, is created in that group.
+func (g *GitLabPlatform) InitializeMirror(ctx context.Context, name string, visibility string) error {
+	path, err := g.mirrorPath(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// Look before creating: tokens without permission to create projects
+	// can still push to one that exists
+	_, _, err = g.client.Projects.GetProject(path, nil, gitlab.WithContext(ctx))
+	switch err = gitlabError(err); {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrRepositoryNotFound):
+	default:
+		return fmt.Errorf("failed to get mirror project: %w", err)
+	}
+
 	vis := gitlab.PrivateVisibility
 	if visibility == "public" {
 		vis = gitlab.PublicVisibility
 	}
 
+	slash := strings.LastIndex(path, "/")
+	namespace, projectName := path[:slash], path[slash+1:]
 	project := &gitlab.CreateProjectOptions{
-		Name:        &name,
+		Name:        &projectName,
+		Path:        &projectName,
 		Description: gitlab.Ptr("Mirror of git activity from other platforms"),
 		Visibility:  &vis,
+		// Without a readme the project is empty and has no default branch
+		DefaultBranch:        gitlab.Ptr(g.mirrorBranch()),
+		InitializeWithReadme: gitlab.Ptr(true),
 	}
 
-	_, _, err := g.client.Projects.CreateProject(project)
-	if err != nil {
-		// Check if project already exists
-		if strings.Contains(err.Error(), "already been taken") {
-			return nil // Project already exists, that's okay
+	// Without a namespace the project is created for the authenticated user
+	if !strings.EqualFold(namespace, g.username) {
+		ns, _, err := g.client.Namespaces.GetNamespace(namespace, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to find mirror namespace %s: %w", namespace, gitlabError(err))
 		}
+		project.NamespaceID = &ns.ID
+	}
+
+	_, _, err = g.client.Projects.CreateProject(project, gitlab.WithContext(ctx))
+	if err = gitlabError(err); err != nil && !errors.Is(err, ErrAlreadyExists) {
 		return fmt.Errorf("failed to create mirror project: %w", err)
 	}
 
 	return nil
 }
 
-// MirrorCommits creates mirror commits with preserved timestamps
-func (g *GitLabPlatform) MirrorCommits(commits []Commit) error {
+// MirrorCommits creates empty mirror commits with preserved author and
+// committer dates locally and pushes them to the mirror branch over HTTPS.
+// The Commits API can't set dates, so it isn't used for writing.
+func (g *GitLabPlatform) MirrorCommits(ctx context.Context, commits []Commit) ([]MirroredCommit, error) {
 	if len(commits) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// Get the mirror project
-	mirrorRepo := g.config.Mirror.Repository
-
-	// Find the project
-	projects, _, err := g.client.Projects.ListProjects(&gitlab.ListProjectsOptions{
-		Search: &mirrorRepo,
-		Owned:  gitlab.Ptr(true),
-	})
+	project, err := g.findMirrorProject(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to find mirror project: %w", err)
+		return nil, err
 	}
 
-	if len(projects) == 0 {
-		return fmt.Errorf("mirror project not found: %s", mirrorRepo)
+	// Access and OAuth tokens authenticate pushes as the password of any
+	// user; a password needs the account's own username
+	auth := g.config.Auth
+	if auth.Type == AuthToken || auth.Type == AuthOAuth || (auth.Type == "" && auth.Token != "") {
+		auth.Username = "oauth2"
 	}
 
-	projectID := projects[0].ID
+	name, email, err := g.mirrorAuthor(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// For each commit, create an empty commit with preserved timestamp
-	for _, commit := range commits {
-		// Create a simple commit message
-		message := fmt.Sprintf("Development work - %s", commit.Date.Format("2006-01-02"))
+	// Pushing to an empty project or missing branch starts it with an
+	// orphan commit
+	m := newGitMirror(project.HTTPURLToRepo, g.mirrorBranch(), mirrorCacheDir(g.config.Extra), auth, name, email, g.config.Timeout)
+	return m.commit(ctx, commits)
+}
 
-		// Create an empty commit using the Commits API
-		// Note: GitLab doesn't have direct support for empty commits via API
-		// This is a simplified version - in practice, you might need to:
-		// 1. Clone the repository locally
-		// 2. Create empty commits with git commands
-		// 3. Push back to GitLab
+// mirrorAuthor returns the author of mirror commits: extra.name and
+// extra.email, falling back to the account's name and its commit or public
+// email, which GitLab attributes to it, and then to the shared git identity
+func (g *GitLabPlatform) mirrorAuthor(ctx context.Context) (string, string, error) {
+	name := extraString(g.config.Extra, "name")
+	email := extraString(g.config.Extra, "email")
 
-		// For now, create a simple file update to simulate activity
-		actions := []*gitlab.CommitActionOptions{
-			{
-				Action:   gitlab.Ptr(gitlab.FileUpdate),
-				FilePath: gitlab.Ptr(".activity"),
-				Content:  gitlab.Ptr(fmt.Sprintf("Activity recorded: %s", commit.Date.Format(time.RFC3339))),
-			},
+	if name == "" || email == "" {
+		user, err := g.currentUser(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		if name == "" {
+			name = user.Name
+		}
+		if email == "" {
+			email = user.CommitEmail
 		}
+		if email == "" {
+			email = user.PublicEmail
+		}
+	}
 
-		createCommitOpt := &gitlab.CreateCommitOptions{
-			Branch:        gitlab.Ptr("main"),
-			CommitMessage: &message,
-			Actions:       actions,
-			AuthorEmail:   gitlab.Ptr(g.config.Auth.Username + "@users.noreply.gitlab.com"),
-			AuthorName:    gitlab.Ptr(g.config.Auth.Username),
+	if name == "" || email == "" {
+		fallbackName, fallbackEmail := mirrorIdentity(ctx, g.config)
+		if name == "" {
+			name = fallbackName
+		}
+		if email == "" {
+			email = fallbackEmail
 		}
+	}
+	return name, email, nil
+}
+
+// gitlabUser is the authenticated user along with the commit email, which
+// the client's User leaves out
+type gitlabUser struct {
+	gitlab.User
+	CommitEmail string `json:"commit_email"`
+}
+
+// currentUser returns the authenticated user
+func (g *GitLabPlatform) currentUser(ctx context.Context) (*gitlabUser, error) {
+	req, err := g.client.NewRequest(http.MethodGet, "user", nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	var user gitlabUser
+	if _, err := g.client.Do(req, &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", gitlabError(err))
+	}
+	return &user, nil
+}
+
+// ListMirrorCommits returns the commits already on the mirror branch since a specific date
+func (g *GitLabPlatform) ListMirrorCommits(ctx context.Context, since time.Time) ([]Commit, error) {
+	var allCommits []Commit
+
+	project, err := g.findMirrorProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := &gitlab.ListCommitsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		Since:       &since,
+		RefName:     gitlab.Ptr(g.mirrorBranch()),
+	}
 
-		_, _, err := g.client.Commits.CreateCommit(projectID, createCommitOpt)
+	for {
+		commits, resp, err := g.client.Commits.ListCommits(project.ID, opt, gitlab.WithContext(ctx))
 		if err != nil {
-			// Try master branch if main doesn't exist
-			createCommitOpt.Branch = gitlab.Ptr("master")
-			_, _, err = g.client.Commits.CreateCommit(projectID, createCommitOpt)
-			if err != nil {
-				return fmt.Errorf("failed to create mirror commit: %w", err)
+			// An empty project has no history yet
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, nil
 			}
+			return nil, fmt.Errorf("failed to list mirror commits: %w", gitlabError(err))
+		}
+
+		for _, commit := range commits {
+			allCommits = append(allCommits, Commit{
+				SHA:      commit.ID,
+				Message:  commit.Message,
+				Date:     *commit.AuthoredDate,
+				URL:      commit.WebURL,
+				Repo:     project.PathWithNamespace,
+				Platform: "gitlab",
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
 		}
+		opt.Page = resp.NextPage
 	}
 
-	return nil
+	return allCommits, nil
 }
 
 // GetMirrorStatus returns the status of the mirror project
-func (g *GitLabPlatform) GetMirrorStatus() (MirrorStatus, error) {
-	mirrorRepo := g.config.Mirror.Repository
-
-	// Find the project
-	projects, _, err := g.client.Projects.ListProjects(&gitlab.ListProjectsOptions{
-		Search: &mirrorRepo,
-		Owned:  gitlab.Ptr(true),
-	})
+func (g *GitLabPlatform) GetMirrorStatus(ctx context.Context) (MirrorStatus, error) {
+	project, err := g.findMirrorProject(ctx)
 	if err != nil {
-		return MirrorStatus{}, fmt.Errorf("failed to find mirror project: %w", err)
+		return MirrorStatus{}, err
 	}
 
-	if len(projects) == 0 {
-		return MirrorStatus{}, fmt.Errorf("mirror project not found: %s", mirrorRepo)
-	}
-
-	project := projects[0]
-
 	// Get latest commit
 	commits, _, err := g.client.Commits.ListCommits(project.ID, &gitlab.ListCommitsOptions{
+		RefName:     gitlab.Ptr(g.mirrorBranch()),
 		ListOptions: gitlab.ListOptions{PerPage: 1},
-	})
+	}, gitlab.WithContext(ctx))
 	if err != nil {
-		return MirrorStatus{}, fmt.Errorf("failed to get latest commit: %w", err)
+		return MirrorStatus{}, fmt.Errorf("failed to get latest commit: %w", gitlabError(err))
 	}
 
 	status := MirrorStatus{
@@ -313,6 +438,58 @@ func (g *GitLabPlatform) GetMirrorStatus() (MirrorStatus, error) {
 	return status, nil
 }
 
+// findMirrorProject looks up the configured mirror project by its full path
+func (g *GitLabPlatform) findMirrorProject(ctx context.Context) (*gitlab.Project, error) {
+	path, err := g.mirrorPath(ctx, g.config.Mirror.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	project, _, err := g.client.Projects.GetProject(path, nil, gitlab.WithContext(ctx))
+	if err = gitlabError(err); err != nil {
+		if errors.Is(err, ErrRepositoryNotFound) {
+			return nil, fmt.Errorf("mirror project %s: %w", path, ErrRepositoryNotFound)
+		}
+		return nil, fmt.Errorf("failed to find mirror project: %w", err)
+	}
+
+	return project, nil
+}
+
+// mirrorPath returns the full path of a mirror project. A name without a
+// namespace is in the authenticated user's, where InitializeMirror creates it.
+func (g *GitLabPlatform) mirrorPath(ctx context.Context, name string) (string, error) {
+	if g.username == "" {
+		if err := g.ValidateCredentials(ctx); err != nil {
+			return "", fmt.Errorf("failed to find mirror project: %w", err)
+		}
+	}
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	return g.username + "/" + name, nil
+}
+
+// mirrorBranch returns the configured mirror branch
+func (g *GitLabPlatform) mirrorBranch() string {
+	if g.config.Mirror.Branch != "" {
+		return g.config.Mirror.Branch
+	}
+	return "main"
+}
+
+// gitlabError tags a GitLab API error with the common platform error it
+// stands for
+func gitlabError(err error) error {
+	var response *gitlab.ErrorResponse
+	if errors.As(err, &response) && response.Response != nil {
+		if kind := errorForStatus(response.Response.StatusCode, response.Message); kind != nil {
+			return &platformError{kind: kind, err: err}
+		}
+	}
+	return err
+}
+
 // GetPlatformName returns the platform name
 func (g *GitLabPlatform) GetPlatformName() string {
 	if g.config.Host != "" && g.config.Host != "gitlab.com" {
@@ -0,0 +1,159 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestGitLab returns a GitLab platform talking to a fake API
+func newTestGitLab(t *testing.T, config PlatformConfig, handler http.Handler) *GitLabPlatform {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config.Host = server.URL
+	g, err := NewGitLabPlatform(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGitLabMirrorCommitsAuthor(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tests := []struct {
+		name  string
+		extra map[string]interface{}
+		want  string
+	}{
+		{"commit email", nil, "Jane Doe <123-jane@users.noreply.gitlab.com>"},
+		{"configured email", map[string]interface{}{"email": "jane@example.com"}, "Jane Doe <jane@example.com>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newBareRepository(t)
+
+			extra := map[string]interface{}{"cache_dir": t.TempDir()}
+			for key, value := range tt.extra {
+				extra[key] = value
+			}
+
+			g := newTestGitLab(t, PlatformConfig{
+				Auth:   AuthConfig{Type: AuthToken, Token: "token"},
+				Mirror: MirrorConfig{Repository: "jane/mirror", Branch: "main"},
+				Extra:  extra,
+			}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.EscapedPath() {
+				case "/api/v4/user":
+					writeJSON(t, w, map[string]interface{}{
+						"id":           123,
+						"username":     "jane",
+						"name":         "Jane Doe",
+						"public_email": "jane@public.example.com",
+						"commit_email": "123-jane@users.noreply.gitlab.com",
+					})
+				case "/api/v4/projects/jane%2Fmirror":
+					writeJSON(t, w, map[string]interface{}{
+						"id":                  1,
+						"path_with_namespace": "jane/mirror",
+						"http_url_to_repo":    remote,
+					})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusForbidden)
+				}
+			}))
+
+			date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			if _, err := g.MirrorCommits(context.Background(), []Commit{{SHA: "a", Message: "Development work", Date: date}}); err != nil {
+				t.Fatal(err)
+			}
+
+			got := gitLog(t, remote, "main", "%an <%ae>|%cn <%ce>")
+			if want := tt.want + "|" + tt.want; strings.Join(got, "\n") != want {
+				t.Errorf("mirror commits by %v, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestGitLabInitializeMirrorCreatesOnlyMissingProjects(t *testing.T) {
+	tests := []struct {
+		repository string
+		exists     bool
+		namespace  int // namespace_id sent on create (0: none)
+	}{
+		{"mirror", true, 0},
+		{"mirror", false, 0},
+		{"group/mirror", true, 0},
+		{"group/mirror", false, 42},
+	}
+
+	for _, tt := range tests {
+		var created map[string]interface{}
+		g := newTestGitLab(t, PlatformConfig{
+			Auth:   AuthConfig{Type: AuthToken, Token: "token"},
+			Mirror: MirrorConfig{Repository: tt.repository, Branch: "main"},
+		}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := "jane/mirror"
+			if strings.Contains(tt.repository, "/") {
+				path = tt.repository
+			}
+
+			switch {
+			case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/user":
+				writeJSON(t, w, map[string]interface{}{"id": 123, "username": "jane"})
+			case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/projects/"+url.PathEscape(path):
+				if !tt.exists {
+					w.WriteHeader(http.StatusNotFound)
+					writeJSON(t, w, map[string]string{"message": "404 Project Not Found"})
+					return
+				}
+				writeJSON(t, w, map[string]interface{}{"id": 1, "path_with_namespace": path})
+			case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/namespaces/group":
+				writeJSON(t, w, map[string]interface{}{"id": 42, "full_path": "group"})
+			case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects":
+				if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+					t.Error(err)
+				}
+				writeJSON(t, w, map[string]interface{}{"id": 1, "path_with_namespace": path})
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}))
+
+		if err := g.InitializeMirror(context.Background(), tt.repository, "private"); err != nil {
+			t.Fatalf("%s: %v", tt.repository, err)
+		}
+
+		if tt.exists {
+			if created != nil {
+				t.Errorf("%s exists but was created", tt.repository)
+			}
+			continue
+		}
+		if created == nil {
+			t.Fatalf("%s is missing but wasn't created", tt.repository)
+		}
+		if created["path"] != "mirror" || created["name"] != "mirror" {
+			t.Errorf("%s created as %v/%v, want mirror", tt.repository, created["name"], created["path"])
+		}
+		namespace, _ := created["namespace_id"].(float64)
+		if int(namespace) != tt.namespace {
+			t.Errorf("%s created in namespace %v, want %d", tt.repository, created["namespace_id"], tt.namespace)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package platforms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitMirror keeps a local bare clone of a mirror branch, appends empty
+// commits with exact author and committer dates to it, and pushes them to
+// the remote with the git command line
+type gitMirror struct {
+	dir    string // local bare repository
+	remote string
+	branch string
+	name   string
+	email  string
+	env    []string // authentication for fetch and push
+
+	timeout time.Duration // limit on each git command (0: none)
+}
+
+// newGitMirror creates a git mirror of a remote branch cached under cacheDir,
+// each git command limited to timeout. HTTPS remotes authenticate with the
+// token or password as a basic auth header, SSH remotes with auth.ssh_key.
+func newGitMirror(remote, branch, cacheDir string, auth AuthConfig, name, email string, timeout time.Duration) *gitMirror {
+	sum := sha256.Sum256([]byte(remote + "\x00" + branch))
+
+	m := &gitMirror{
+		dir:    filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+".git"),
+		remote: remote,
+		branch: branch,
+		name:   name,
+		email:  email,
+
+		timeout: timeout,
+	}
+
+	secret := auth.Token
+	if secret == "" {
+		secret = auth.Password
+	}
+	if secret != "" && (strings.HasPrefix(remote, "https://") || strings.HasPrefix(remote, "http://")) {
+		user := auth.Username
+		if user == "" {
+			user = "git"
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(user + ":" + secret))
+
+		// Passed through the environment rather than arguments or the
+		// repository config so the secret isn't visible or persisted
+		m.env = append(m.env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	if auth.SSHKey != "" {
+		m.env = append(m.env, "GIT_SSH_COMMAND=ssh -i "+strconv.Quote(expandPath(auth.SSHKey))+" -o IdentitiesOnly=yes")
+	}
+
+	return m
+}
+
+// mirrorCacheDir is where local mirror clones are kept: extra.cache_dir, or
+// a directory next to the rest of the tool's state
+func mirrorCacheDir(extra map[string]interface{}) string {
+	if dir := extraString(extra, "cache_dir"); dir != "" {
+		return expandPath(dir)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "git-activity-mirror", "mirrors")
+	}
+	return filepath.Join(home, ".git-activity-mirror", "mirrors")
+}
+
+// sync brings the local branch in line with the remote, creating the local
+// repository on first use. A branch missing on the remote is dropped locally
+// too, so commits that failed to push are never pushed later unrecorded.
+func (m *gitMirror) sync(ctx context.Context) error {
+	if _, err := os.Stat(m.dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(m.dir), 0755); err != nil {
+			return fmt.Errorf("failed to create mirror cache: %w", err)
+		}
+		if _, err := m.git(ctx, nil, "", "init", "--quiet", "--bare", m.dir); err != nil {
+			return err
+		}
+	}
+
+	heads, err := m.git(ctx, m.env, "", "ls-remote", "--heads", m.remote, m.ref())
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(heads) == "" {
+		_, err := m.git(ctx, nil, "", "update-ref", "-d", m.ref())
+		return err
+	}
+
+	_, err = m.git(ctx, m.env, "", "fetch", "--quiet", "--no-tags", m.remote, "+"+m.ref()+":"+m.ref())
+	return err
+}
+
+// head returns the commit the local branch points at, or "" for a new branch
+func (m *gitMirror) head(ctx context.Context) (string, error) {
+	out, err := m.git(ctx, nil, "", "for-each-ref", "--format=%(objectname)", m.ref())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// commit appends an empty commit per mirrored commit and pushes them all at
+// once. Nothing is reported as mirrored unless the push succeeds.
+func (m *gitMirror) commit(ctx context.Context, commits []Commit) ([]MirroredCommit, error) {
+	if err := m.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	old, err := m.head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := m.git(ctx, nil, "", "mktree")
+	if err != nil {
+		return nil, err
+	}
+	tree = strings.TrimSpace(tree)
+
+	var mirrored []MirroredCommit
+	parent := old
+	for _, commit := range commits {
+		date := fmt.Sprintf("@%d %s", commit.Date.Unix(), commit.Date.Format("-0700"))
+		env := []string{
+			"GIT_AUTHOR_NAME=" + m.name,
+			"GIT_AUTHOR_EMAIL=" + m.email,
+			"GIT_AUTHOR_DATE=" + date,
+			"GIT_COMMITTER_NAME=" + m.name,
+			"GIT_COMMITTER_EMAIL=" + m.email,
+			"GIT_COMMITTER_DATE=" + date,
+		}
+
+		args := []string{"commit-tree", tree}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+
+		sha, err := m.git(ctx, env, commit.Message, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mirror commit: %w", err)
+		}
+		parent = strings.TrimSpace(sha)
+
+		mirrored = append(mirrored, MirroredCommit{
+			SourceSHA: commit.SHA,
+			MirrorSHA: parent,
+		})
+	}
+
+	if _, err := m.git(ctx, m.env, "", "push", "--quiet", m.remote, parent+":"+m.ref()); err != nil {
+		return nil, fmt.Errorf("failed to push mirror commits: %w", err)
+	}
+
+	if _, err := m.git(ctx, nil, "", "update-ref", m.ref(), parent); err != nil {
+		return mirrored, err
+	}
+
+	return mirrored, nil
+}
+
+// list returns the commits on the mirror branch since a specific date
+func (m *gitMirror) list(ctx context.Context, since time.Time) ([]Commit, error) {
+	if err := m.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	head, err := m.head(ctx)
+	if err != nil || head == "" {
+		return nil, err
+	}
+
+	out, err := m.git(ctx, nil, "", "log", head,
+		"--since="+since.Format(time.RFC3339),
+		"--format=%H%x1f%aI%x1f%B%x1e",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x1f", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git log output")
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", fields[1], err)
+		}
+
+		commits = append(commits, Commit{
+			SHA:     fields[0],
+			Message: strings.TrimRight(fields[2], "\n"),
+			Date:    date,
+		})
+	}
+
+	return commits, nil
+}
+
+func (m *gitMirror) ref() string {
+	return "refs/heads/" + m.branch
+}
+
+// git runs a git command against the local repository with extra
+// environment and standard input
+func (m *gitMirror) git(ctx context.Context, env []string, stdin string, args ...string) (string, error) {
+	if args[0] != "init" {
+		args = append([]string{"--git-dir", m.dir}, args...)
+	}
+
+	ctx, cancel := withTimeout(ctx, m.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		name := args[0]
+		if name == "--git-dir" {
+			name = args[2]
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", name, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", name, err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,109 @@
+package platforms
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newBareRepository creates an empty bare repository to push mirrors to
+func newBareRepository(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	return dir
+}
+
+// gitLog returns one formatted line per commit on a branch, newest first
+func gitLog(t *testing.T, dir, branch, format string) []string {
+	t.Helper()
+
+	out, err := exec.Command("git", "--git-dir", dir, "log", "--format="+format, branch).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v: %s", err, out)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+func TestGitMirrorPushesExactDates(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote := newBareRepository(t)
+	target := func() *GenericGitPlatform {
+		// A fresh cache each time, as on another machine
+		g, err := NewGenericGitPlatform(PlatformConfig{
+			Mirror: MirrorConfig{Repository: "file://" + remote, Branch: "main"},
+			Extra:  map[string]interface{}{"name": "Jane Doe", "email": "jane@example.com", "cache_dir": t.TempDir()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	ctx := context.Background()
+	zone := time.FixedZone("", -5*60*60)
+	first := []Commit{
+		{SHA: "a", Message: "Development work", Date: time.Date(2024, 1, 15, 8, 0, 0, 0, zone)},
+		{SHA: "b", Message: "Development work", Date: time.Date(2024, 1, 15, 23, 59, 59, 0, zone)},
+	}
+	second := []Commit{
+		{SHA: "c", Message: "Development work", Date: time.Date(2024, 1, 16, 12, 30, 0, 0, zone)},
+	}
+
+	g := target()
+	mirrored, err := g.MirrorCommits(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mirrored) != 2 {
+		t.Fatalf("mirrored %d commits, want 2", len(mirrored))
+	}
+
+	want := []string{
+		"2024-01-15T23:59:59-05:00 2024-01-15T23:59:59-05:00",
+		"2024-01-15T08:00:00-05:00 2024-01-15T08:00:00-05:00",
+	}
+	if got := gitLog(t, remote, "main", "%aI %cI"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("mirror history:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A rerun appends on top of what is already pushed, leaving it untouched
+	more, err := target().MirrorCommits(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = append([]string{"2024-01-16T12:30:00-05:00 2024-01-16T12:30:00-05:00"}, want...)
+	if got := gitLog(t, remote, "main", "%aI %cI"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("mirror history after rerun:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	shas := gitLog(t, remote, "main", "%H")
+	wantSHAs := []string{more[0].MirrorSHA, mirrored[1].MirrorSHA, mirrored[0].MirrorSHA}
+	if strings.Join(shas, ",") != strings.Join(wantSHAs, ",") {
+		t.Errorf("mirror commits = %v, want %v", shas, wantSHAs)
+	}
+
+	// The pushed dates read back exactly, so reruns can skip them
+	existing, err := g.ListMirrorCommits(ctx, first[0].Date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing) != 3 {
+		t.Fatalf("listed %d mirror commits, want 3", len(existing))
+	}
+	for i, commit := range append(second, first[1], first[0]) {
+		if !existing[i].Date.Equal(commit.Date) {
+			t.Errorf("mirror commit %d dated %s, want %s", i, existing[i].Date, commit.Date)
+		}
+	}
+}
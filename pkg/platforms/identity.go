@@ -0,0 +1,124 @@
+package platforms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Identities lists the names, emails and platform usernames that count as
+// the user. Commits neither authored nor committed by one of them are left
+// out of the mirror.
+type Identities struct {
+	Names     []string `yaml:"names,omitempty"`
+	Emails    []string `yaml:"emails,omitempty"`
+	Usernames []string `yaml:"usernames,omitempty"`
+}
+
+// IsZero reports whether no identity is listed
+func (i Identities) IsZero() bool {
+	return len(i.Names) == 0 && len(i.Emails) == 0 && len(i.Usernames) == 0
+}
+
+// Merge returns the identities listed in either i or other
+func (i Identities) Merge(other Identities) Identities {
+	return Identities{
+		Names:     append(append([]string(nil), i.Names...), other.Names...),
+		Emails:    append(append([]string(nil), i.Emails...), other.Emails...),
+		Usernames: append(append([]string(nil), i.Usernames...), other.Usernames...),
+	}
+}
+
+// Matches reports whether a commit was authored or committed by one of the
+// identities. Names, emails and usernames are compared case-insensitively.
+func (i Identities) Matches(commit Commit) bool {
+	return i.matchesAuthor(commit.Author) || i.matchesAuthor(commit.Committer)
+}
+
+func (i Identities) matchesAuthor(author Author) bool {
+	return containsFold(i.Names, author.Name) ||
+		containsFold(i.Emails, author.Email) ||
+		containsFold(i.Usernames, author.Username)
+}
+
+// containsFold reports whether a non-empty value is in values, ignoring case
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// configuredIdentities returns the identities set for a platform: the
+// identities section and extra.authors, whose entries may be any of a name,
+// an email or a username
+func configuredIdentities(config PlatformConfig) Identities {
+	authors := extraStrings(config.Extra, "authors")
+	return config.Identities.Merge(Identities{Names: authors, Emails: authors, Usernames: authors})
+}
+
+// identityFilter leaves commits by other people out of GetCommits, counting
+// how many it drops. Platforms embed it to implement ExcludedCommits and
+// OwnCommits.
+type identityFilter struct {
+	identities Identities
+	mailmap    *Mailmap
+	excluded   atomic.Int64
+}
+
+// newIdentityFilter creates a filter for the configured identities, falling
+// back to the auth username and then to the global git user.email
+func newIdentityFilter(config PlatformConfig) *identityFilter {
+	identities := configuredIdentities(config)
+	if identities.IsZero() && config.Auth.Username != "" {
+		username := []string{config.Auth.Username}
+		identities = Identities{Names: username, Emails: username, Usernames: username}
+	}
+	if identities.IsZero() {
+		if out, err := runGit(context.Background(), ".", "config", "--global", "user.email"); err == nil {
+			if email := strings.TrimSpace(string(out)); email != "" {
+				identities.Emails = []string{email}
+			}
+		}
+	}
+	return &identityFilter{identities: identities, mailmap: config.Mailmap}
+}
+
+// keep returns the commits made by one of the identities, with authors and
+// committers resolved through the mailmap. Without any identity it fails
+// with ErrNoIdentity rather than taking everyone's commits for the user's.
+func (f *identityFilter) keep(commits []Commit) ([]Commit, error) {
+	if f.identities.IsZero() {
+		return nil, fmt.Errorf("%w - set identities, extra.authors or auth.username, or git config --global user.email", ErrNoIdentity)
+	}
+
+	for i := range commits {
+		commits[i].Author = f.mailmap.Resolve(commits[i].Author)
+		commits[i].Committer = f.mailmap.Resolve(commits[i].Committer)
+	}
+
+	kept := make([]Commit, 0, len(commits))
+	for _, commit := range commits {
+		if f.identities.Matches(commit) {
+			kept = append(kept, commit)
+		}
+	}
+	f.excluded.Add(int64(len(commits) - len(kept)))
+	return kept, nil
+}
+
+// OwnCommits filters commits read elsewhere, e.g. from a push webhook
+func (f *identityFilter) OwnCommits(ctx context.Context, commits []Commit) ([]Commit, error) {
+	return f.keep(commits)
+}
+
+// ExcludedCommits returns how many commits have been left out so far
+func (f *identityFilter) ExcludedCommits() int {
+	return int(f.excluded.Load())
+}
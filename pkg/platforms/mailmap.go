@@ -0,0 +1,121 @@
+package platforms
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Mailmap maps the names and emails commits were made under to canonical
+// ones, as git's .mailmap files do
+type Mailmap struct {
+	// entries are keyed by lower case commit email, then by lower case
+	// commit name, with "" for entries that match any name
+	entries map[string]map[string]Author
+}
+
+// LoadMailmap reads mailmap files in git's format, later entries overriding
+// earlier ones. It returns nil when no paths are given. Each line is one of:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func LoadMailmap(paths ...string) (*Mailmap, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	m := &Mailmap{entries: make(map[string]map[string]Author)}
+	for _, path := range paths {
+		f, err := os.Open(expandPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open mailmap: %w", err)
+		}
+		err = m.parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mailmap %s: %w", path, err)
+		}
+	}
+	return m, nil
+}
+
+// parse adds the entries of a mailmap
+func (m *Mailmap) parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		properName, properEmail, rest, ok := cutMailmapPerson(text)
+		if !ok {
+			return fmt.Errorf("line %d: expected an email in angle brackets", line)
+		}
+
+		// With a single email, it is the commit email and only the name is
+		// replaced
+		commitName, commitEmail, _, ok := cutMailmapPerson(rest)
+		if !ok {
+			commitName, commitEmail, properEmail = "", properEmail, ""
+		}
+
+		byName := m.entries[strings.ToLower(commitEmail)]
+		if byName == nil {
+			byName = make(map[string]Author)
+			m.entries[strings.ToLower(commitEmail)] = byName
+		}
+		byName[strings.ToLower(commitName)] = Author{Name: properName, Email: properEmail}
+	}
+	return scanner.Err()
+}
+
+// cutMailmapPerson splits an optional name and an <email> off the front of s
+func cutMailmapPerson(s string) (name, email, rest string, ok bool) {
+	open := strings.IndexByte(s, '<')
+	if open < 0 {
+		return "", "", s, false
+	}
+	end := strings.IndexByte(s[open:], '>')
+	if end < 0 {
+		return "", "", s, false
+	}
+	end += open
+
+	return strings.TrimSpace(s[:open]), strings.TrimSpace(s[open+1 : end]), s[end+1:], true
+}
+
+// Resolve returns the canonical form of an author. Entries matching both
+// name and email win over entries matching the email alone; authors without
+// an entry are returned unchanged.
+func (m *Mailmap) Resolve(author Author) Author {
+	if m == nil {
+		return author
+	}
+
+	byName, ok := m.entries[strings.ToLower(author.Email)]
+	if !ok {
+		return author
+	}
+	proper, ok := byName[strings.ToLower(author.Name)]
+	if !ok {
+		if proper, ok = byName[""]; !ok {
+			return author
+		}
+	}
+
+	if proper.Name != "" {
+		author.Name = proper.Name
+	}
+	if proper.Email != "" {
+		author.Email = proper.Email
+	}
+	return author
+}
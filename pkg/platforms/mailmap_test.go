@@ -0,0 +1,84 @@
+package platforms
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMailmapResolve(t *testing.T) {
+	const mailmap = `# Canonical identities
+Jane Doe <jane@old.example.com>
+<jane@example.com> <jane@laptop.local>
+Jane Doe <jane@example.com> <JDoe@Work.Example.com>   # trailing comment
+Jane Doe <jane@example.com> jd <shared@example.com>
+Ci Bot <ci@example.com> <shared@example.com>
+
+   # indented comment
+`
+
+	tests := []struct {
+		name   string
+		author Author
+		want   Author
+	}{
+		{"proper name", Author{Name: "jane", Email: "jane@old.example.com"}, Author{Name: "Jane Doe", Email: "jane@old.example.com"}},
+		{"proper email", Author{Name: "jane", Email: "jane@laptop.local"}, Author{Name: "jane", Email: "jane@example.com"}},
+		{"proper name and email", Author{Name: "jdoe", Email: "jdoe@work.example.com"}, Author{Name: "Jane Doe", Email: "jane@example.com"}},
+		{"email case", Author{Name: "jdoe", Email: "JANE@OLD.EXAMPLE.COM"}, Author{Name: "Jane Doe", Email: "JANE@OLD.EXAMPLE.COM"}},
+		{"commit name and email", Author{Name: "JD", Email: "shared@example.com"}, Author{Name: "Jane Doe", Email: "jane@example.com"}},
+		{"email only", Author{Name: "someone", Email: "shared@example.com"}, Author{Name: "Ci Bot", Email: "ci@example.com"}},
+		{"no entry", Author{Name: "John", Email: "john@example.com"}, Author{Name: "John", Email: "john@example.com"}},
+	}
+
+	m := &Mailmap{entries: make(map[string]map[string]Author)}
+	if err := m.parse(strings.NewReader(mailmap)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Resolve(tt.author); got != tt.want {
+				t.Errorf("Resolve(%v) = %v, want %v", tt.author, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailmapParseInvalid(t *testing.T) {
+	m := &Mailmap{entries: make(map[string]map[string]Author)}
+	err := m.parse(strings.NewReader("Jane Doe <jane@example.com>\nJane Doe jane@example.com\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("parse() = %v, want an error on line 2", err)
+	}
+}
+
+func TestLoadMailmap(t *testing.T) {
+	if m, err := LoadMailmap(); m != nil || err != nil {
+		t.Errorf("LoadMailmap() = %v, %v, want nil", m, err)
+	}
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	if err := os.WriteFile(first, []byte("Jane <jane@example.com>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("Jane Doe <jane@example.com>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Later files override earlier ones
+	m, err := LoadMailmap(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Resolve(Author{Name: "jane", Email: "jane@example.com"}); got.Name != "Jane Doe" {
+		t.Errorf("Resolve() = %v, want Jane Doe", got)
+	}
+
+	if _, err := LoadMailmap(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadMailmap() of a missing file succeeded")
+	}
+}
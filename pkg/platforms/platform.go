@@ -1,27 +1,42 @@
 package platforms
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 )
 
 // GitPlatform defines the interface that all git hosting platforms must implement
-// This enables platform-agnostic mirroring between any git hosts
+// This enables platform-agnostic mirroring between any git hosts. Cancelling
+// the context passed to a call aborts its API requests and git commands.
 type GitPlatform interface {
 	// Authentication and connection
-	Connect(config AuthConfig) error
-	ValidateCredentials() error
-	Disconnect() error
+	Connect(ctx context.Context, config AuthConfig) error
+	ValidateCredentials(ctx context.Context) error
+	Disconnect(ctx context.Context) error
 
-	// Source operations (reading commits from source platform)
-	ListRepositories() ([]Repository, error)
-	GetCommits(repo Repository, since time.Time) ([]Commit, error)
-	GetCommitCount(repo Repository, since time.Time) (int, error)
+	// Source operations (reading commits from source platform). GetCommits
+	// returns commits committed since the given time, so rebased or
+	// cherry-picked commits with older author dates are still picked up.
+	ListRepositories(ctx context.Context) ([]Repository, error)
+	GetCommits(ctx context.Context, repo Repository, since time.Time) ([]Commit, error)
+	GetCommitCount(ctx context.Context, repo Repository, since time.Time) (int, error)
 
-	// Target operations (writing mirror commits to target platform)
-	InitializeMirror(name string, visibility string) error
-	MirrorCommits(commits []Commit) error
-	GetMirrorStatus() (MirrorStatus, error)
+	// Identity filtering. GetCommits leaves out commits by anyone outside the
+	// configured identities; OwnCommits does the same for commits read
+	// elsewhere, and ExcludedCommits counts the commits left out so far.
+	OwnCommits(ctx context.Context, commits []Commit) ([]Commit, error)
+	ExcludedCommits() int
+
+	// Target operations (writing mirror commits to target platform).
+	InitializeMirror(ctx context.Context, name string, visibility string) error
+	// MirrorCommits writes each commit's Message and Date as given. Callers
+	// pass the mirror message to write, never the source message.
+	MirrorCommits(ctx context.Context, commits []Commit) ([]MirroredCommit, error)
+	ListMirrorCommits(ctx context.Context, since time.Time) ([]Commit, error)
+	GetMirrorStatus(ctx context.Context) (MirrorStatus, error)
 
 	// Platform information
 	GetPlatformName() string
@@ -38,6 +53,8 @@ const (
 	PlatformBitbucket   PlatformType = "bitbucket"
 	PlatformAzureDevOps PlatformType = "azuredevops"
 	PlatformGenericGit  PlatformType = "generic"
+	PlatformGitea       PlatformType = "gitea"
+	PlatformForgejo     PlatformType = "forgejo"
 )
 
 // AuthConfig holds authentication configuration for a platform
@@ -81,16 +98,34 @@ type Commit struct {
 	Message   string    `json:"message"`
 	Author    Author    `json:"author"`
 	Committer Author    `json:"committer"`
-	Date      time.Time `json:"date"`
+	Date      time.Time `json:"date"`                // author date, what mirrors are dated with
+	Committed time.Time `json:"committed,omitempty"` // committer date (zero when unknown)
 	URL       string    `json:"url"`
 	Repo      string    `json:"repo"`
 	Platform  string    `json:"platform"`
+	Source    string    `json:"source,omitempty"` // configured source name
+}
+
+// CommitDate returns when a commit was committed, falling back to its author
+// date where the platform doesn't report it
+func (c Commit) CommitDate() time.Time {
+	if c.Committed.IsZero() {
+		return c.Date
+	}
+	return c.Committed
 }
 
 // Author represents a commit author/committer
 type Author struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username,omitempty"` // platform login, when known
+}
+
+// MirroredCommit links a source commit to the mirror commit created for it
+type MirroredCommit struct {
+	SourceSHA string `json:"source_sha"`
+	MirrorSHA string `json:"mirror_sha"`
 }
 
 // MirrorStatus represents the status of a mirror repository
@@ -112,6 +147,22 @@ type PlatformConfig struct {
 	Repos    []string               `yaml:"repositories,omitempty"`
 	Mirror   MirrorConfig           `yaml:"mirror,omitempty"`
 	Extra    map[string]interface{} `yaml:"extra,omitempty"`
+
+	// Identities are the people whose commits are read (default: the auth
+	// username)
+	Identities Identities `yaml:"-"`
+
+	// Mailmap maps the authors and committers of commits read to canonical
+	// names and emails before they are matched against Identities (optional)
+	Mailmap *Mailmap `yaml:"-"`
+
+	// RateLimitWait is how long API calls may wait in total for rate limits
+	// to lift (default: DefaultRateLimitWait)
+	RateLimitWait time.Duration `yaml:"-"`
+
+	// Timeout limits each API request and git command (0: none). Rate
+	// limit waits between requests don't count against it.
+	Timeout time.Duration `yaml:"-"`
 }
 
 // MirrorConfig holds mirror-specific configuration
@@ -122,6 +173,41 @@ type MirrorConfig struct {
 	Strategy   string `yaml:"strategy,omitempty"` // unified, separate, hashed
 }
 
+// extraString returns a string setting from a platform's extra section
+func extraString(extra map[string]interface{}, key string) string {
+	if value, ok := extra[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// extraStrings returns a list setting from a platform's extra section,
+// accepting a single string as a one-element list
+func extraStrings(extra map[string]interface{}, key string) []string {
+	switch value := extra[key].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	case []string:
+		return value
+	}
+	return nil
+}
+
+// Mirror strategies
+const (
+	StrategyUnified  = "unified"  // all sources share one mirror repository
+	StrategySeparate = "separate" // one mirror repository per source, named after it
+	StrategyHashed   = "hashed"   // one mirror repository per source, with an obfuscated name
+)
+
 // NewPlatform creates a new platform instance based on the platform type
 func NewPlatform(platformType PlatformType, config PlatformConfig) (GitPlatform, error) {
 	switch platformType {
@@ -129,8 +215,18 @@ func NewPlatform(platformType PlatformType, config PlatformConfig) (GitPlatform,
 		return NewGitHubPlatform(config)
 	case PlatformGitLab:
 		return NewGitLabPlatform(config)
-	case PlatformBitbucket, PlatformAzureDevOps, PlatformGenericGit:
-		return nil, fmt.Errorf("platform %s not implemented yet - coming in v0.2.0", platformType)
+	case PlatformBitbucket:
+		if !isBitbucketCloud(config) {
+			return NewBitbucketServerPlatform(config)
+		}
+		return NewBitbucketPlatform(config)
+	case PlatformAzureDevOps:
+		return NewAzureDevOpsPlatform(config)
+	case PlatformGitea, PlatformForgejo:
+		config.Platform = platformType
+		return NewGiteaPlatform(config)
+	case PlatformGenericGit:
+		return NewGenericGitPlatform(config)
 	default:
 		return nil, ErrUnsupportedPlatform
 	}
@@ -143,4 +239,50 @@ var (
 	ErrRepositoryNotFound  = fmt.Errorf("repository not found")
 	ErrPermissionDenied    = fmt.Errorf("permission denied")
 	ErrRateLimit           = fmt.Errorf("rate limit exceeded")
+	ErrAlreadyExists       = fmt.Errorf("already exists")
+	ErrNoIdentity          = fmt.Errorf("no identity to tell your commits apart")
 )
+
+// errorForStatus returns the common error an API error response stands for,
+// judging by its status code and message, or nil if there is none
+func errorForStatus(status int, message string) error {
+	message = strings.ToLower(message)
+
+	// Duplicates come back as 400, 409 or 422 depending on the platform
+	if status >= 400 && status < 500 &&
+		(strings.Contains(message, "already exists") || strings.Contains(message, "already been taken")) {
+		return ErrAlreadyExists
+	}
+
+	switch status {
+	case http.StatusUnauthorized:
+		return ErrInvalidAuth
+	case http.StatusForbidden:
+		// GitHub reports exhausted and secondary rate limits as 403
+		if strings.Contains(message, "rate limit") {
+			return ErrRateLimit
+		}
+		return ErrPermissionDenied
+	case http.StatusNotFound:
+		return ErrRepositoryNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimit
+	}
+	return nil
+}
+
+// platformError tags an SDK error with the common error it stands for,
+// keeping the SDK's message
+type platformError struct {
+	kind error
+	err  error
+}
+
+func (e *platformError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap matches both the common error and the SDK error
+func (e *platformError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
@@ -0,0 +1,279 @@
+package platforms
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimitWait is how long a platform may wait for rate limits
+	// in total before giving up with ErrRateLimit
+	DefaultRateLimitWait = 15 * time.Minute
+
+	// rateLimitBackoff and maxRateLimitBackoff bound the exponential backoff
+	// used when a rate limited response doesn't say how long to wait
+	rateLimitBackoff    = time.Second
+	maxRateLimitBackoff = 2 * time.Minute
+
+	// secondaryRateLimitBackoff is the least GitHub asks clients to wait
+	// after hitting a secondary rate limit
+	secondaryRateLimitBackoff = time.Minute
+)
+
+// rateLimitTransport retries requests that hit a rate limit once it lifts.
+// It reads the X-RateLimit-* (GitHub, Azure DevOps), RateLimit-* (GitLab)
+// and Retry-After headers, backs off exponentially when a 403 or 429 gives
+// no hint, and waits out an exhausted quota before handing back the response
+// that used it up. Waits are shared across requests and limited to a budget;
+// past it, requests fail with ErrRateLimit. Each attempt, including reading
+// its response body, is limited to a timeout that doesn't run while waiting.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	budget  time.Duration
+	timeout time.Duration
+
+	mu     sync.Mutex
+	waited time.Duration
+}
+
+// newRateLimitTransport wraps base (http.DefaultTransport when nil) with a
+// wait budget, DefaultRateLimitWait when zero, and a per-request timeout, none
+// when zero
+func newRateLimitTransport(base http.RoundTripper, budget, timeout time.Duration) *rateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if budget == 0 {
+		budget = DefaultRateLimitWait
+	}
+	return &rateLimitTransport{base: base, budget: budget, timeout: timeout}
+}
+
+// RoundTrip sends a request, waiting and retrying while it is rate limited
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, timer, err := t.send(r)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, limited := rateLimitWait(resp, attempt)
+		if !limited {
+			// The quota ran out with this request: wait for the reset so the
+			// next one goes through, but keep the response either way. The
+			// timeout is paused meanwhile so the body can still be read.
+			if reset, ok := quotaReset(resp); ok && resp.StatusCode < 400 {
+				if timer == nil || timer.Stop() {
+					_ = t.wait(req.Context(), req.URL.Host, time.Until(reset))
+					if timer != nil {
+						timer.Reset(t.timeout)
+					}
+				}
+			}
+			return resp, nil
+		}
+
+		// A body that can't be sent again can't be retried
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		if err := t.wait(req.Context(), req.URL.Host, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes one attempt at a request. With a timeout, the attempt is
+// cancelled once it has taken that long, reading the response body included;
+// the returned timer (nil without a timeout) enforces it.
+func (t *rateLimitTransport) send(req *http.Request) (*http.Response, *time.Timer, error) {
+	if t.timeout <= 0 {
+		resp, err := t.base.RoundTrip(req)
+		return resp, nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(t.timeout, func() {
+		cancel(fmt.Errorf("%w: no response from %s within %s", context.DeadlineExceeded, req.URL.Host, t.timeout))
+	})
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		timer.Stop()
+		cancel(nil)
+		if req.Context().Err() == nil && ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		return nil, nil, err
+	}
+
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, ctx: ctx, parent: req.Context(), stop: func() {
+		timer.Stop()
+		cancel(nil)
+	}}
+	return resp, timer, nil
+}
+
+// timeoutBody releases a request's timeout once its response body is closed,
+// and reports reads cut short by the timeout as such
+type timeoutBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	parent context.Context
+	stop   func()
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.parent.Err() == nil && b.ctx.Err() != nil {
+		err = context.Cause(b.ctx)
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.stop()
+	return err
+}
+
+// wait sleeps for d, charged to the budget. It fails with ErrRateLimit
+// without sleeping if the budget or the request's deadline doesn't allow it.
+func (t *rateLimitTransport) wait(ctx context.Context, host string, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return fmt.Errorf("%w by %s: retry in %s, past the request deadline", ErrRateLimit, host, d.Round(time.Second))
+	}
+
+	t.mu.Lock()
+	if t.waited+d > t.budget {
+		waited := t.waited
+		t.mu.Unlock()
+		return fmt.Errorf("%w by %s: retry in %s, over the %s wait budget (%s used)",
+			ErrRateLimit, host, d.Round(time.Second), t.budget, waited.Round(time.Second))
+	}
+	t.waited += d
+	t.mu.Unlock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitWait reports whether a response was rejected by a rate limit, and
+// how long to wait before retrying
+func rateLimitWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusForbidden {
+		return 0, false
+	}
+
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, true
+	}
+	if reset, ok := quotaReset(resp); ok {
+		return time.Until(reset) + time.Second, true
+	}
+
+	backoff := rateLimitBackoff << attempt
+	if backoff <= 0 || backoff > maxRateLimitBackoff {
+		backoff = maxRateLimitBackoff
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return backoff, true
+	}
+
+	// Any other 403 is a real permission error, unless it is GitHub's
+	// secondary rate limit, which only says so in the body
+	if !secondaryRateLimited(resp) {
+		return 0, false
+	}
+	if backoff < secondaryRateLimitBackoff {
+		backoff = secondaryRateLimitBackoff
+	}
+	return backoff, true
+}
+
+// quotaReset returns when an exhausted request quota resets, if the response
+// says the quota is used up
+func quotaReset(resp *http.Response) (time.Time, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		remaining := resp.Header.Get(prefix + "Remaining")
+		if remaining == "" {
+			continue
+		}
+		if n, err := strconv.ParseFloat(remaining, 64); err != nil || n > 0 {
+			return time.Time{}, false
+		}
+
+		reset, err := strconv.ParseInt(resp.Header.Get(prefix+"Reset"), 10, 64)
+		if err != nil || reset <= 0 {
+			return time.Time{}, false
+		}
+		// Usually an epoch time; small values are seconds from now
+		if reset < 1e9 {
+			return time.Now().Add(time.Duration(reset) * time.Second), true
+		}
+		return time.Unix(reset, 0), true
+	}
+	return time.Time{}, false
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
+
+// secondaryRateLimited reports whether a 403 response is a secondary rate
+// limit. The body is read and put back for the caller.
+func secondaryRateLimited(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
@@ -0,0 +1,233 @@
+package platforms
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWaitsDontCountAgainstTimeout(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRateLimitTransport(nil, time.Minute, 500*time.Millisecond)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" || attempts != 2 {
+		t.Errorf("got %q after %d attempts, want ok after 2", body, attempts)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Transport: newRateLimitTransport(nil, time.Minute, 100*time.Millisecond)}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want a timeout", err)
+	}
+}
+
+func TestRequestTimeoutReadingBody(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Transport: newRateLimitTransport(nil, time.Minute, 100*time.Millisecond)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("reading the body: error = %v, want a timeout", err)
+	}
+}
+
+func TestRateLimitWaitBudget(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Path == "/long" {
+			w.Header().Set("Retry-After", "120")
+		} else {
+			w.Header().Set("Retry-After", "1")
+		}
+		if attempts%2 == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRateLimitTransport(nil, 1500*time.Millisecond, 0)}
+
+	// A wait longer than the whole budget fails straight away
+	if _, err := client.Get(server.URL + "/long"); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("error = %v, want ErrRateLimit", err)
+	}
+	if attempts != 1 {
+		t.Errorf("%d attempts, want 1", attempts)
+	}
+
+	// The budget is shared: the second wait doesn't fit in what's left of it
+	attempts = 0
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("error = %v, want ErrRateLimit once the budget is used", err)
+	}
+	if attempts != 3 {
+		t.Errorf("%d attempts, want 3", attempts)
+	}
+}
+
+func TestRateLimitWaitPastDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: newRateLimitTransport(nil, time.Hour, 0)}
+	if _, err := client.Do(req); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("error = %v, want ErrRateLimit", err)
+	}
+}
+
+func TestRateLimitWaitResponses(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute)
+	epoch := strconv.FormatInt(reset.Unix(), 10)
+
+	tests := []struct {
+		name    string
+		status  int
+		header  map[string]string
+		body    string
+		attempt int
+		limited bool
+		wait    time.Duration // expected wait, give or take a couple of seconds
+	}{
+		{"ok", http.StatusOK, nil, "", 0, false, 0},
+		{"retry after seconds", http.StatusTooManyRequests, map[string]string{"Retry-After": "30"}, "", 0, true, 30 * time.Second},
+		{"retry after date", http.StatusTooManyRequests, map[string]string{"Retry-After": reset.UTC().Format(http.TimeFormat)}, "", 0, true, 10 * time.Minute},
+		{"unavailable", http.StatusServiceUnavailable, map[string]string{"Retry-After": "30"}, "", 0, false, 0},
+		{"github quota", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch}, "", 0, true, 10 * time.Minute},
+		{"gitlab quota", http.StatusTooManyRequests, map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": epoch}, "", 0, true, 10 * time.Minute},
+		{"relative reset", http.StatusTooManyRequests, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "90"}, "", 0, true, 90 * time.Second},
+		{"quota left", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "12", "X-RateLimit-Reset": epoch}, `{"message":"Resource not accessible"}`, 0, false, 0},
+		{"backoff", http.StatusTooManyRequests, nil, "", 3, true, 8 * time.Second},
+		{"backoff cap", http.StatusTooManyRequests, nil, "", 20, true, maxRateLimitBackoff},
+		{"secondary rate limit", http.StatusForbidden, nil, `{"message":"You have exceeded a secondary rate limit."}`, 0, true, secondaryRateLimitBackoff},
+		{"abuse detection", http.StatusForbidden, nil, `{"message":"You have triggered an abuse detection mechanism."}`, 3, true, secondaryRateLimitBackoff},
+		{"permission denied", http.StatusForbidden, nil, `{"message":"Must have admin rights to Repository."}`, 0, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tt.body))}
+			for key, value := range tt.header {
+				resp.Header.Set(key, value)
+			}
+
+			wait, limited := rateLimitWait(resp, tt.attempt)
+			if limited != tt.limited {
+				t.Fatalf("limited = %v, want %v", limited, tt.limited)
+			}
+			if diff := wait - tt.wait; diff < -2*time.Second || diff > 2*time.Second {
+				t.Errorf("wait = %s, want %s", wait, tt.wait)
+			}
+
+			// Reading a 403 body for the secondary limit must leave it intact
+			body, err := io.ReadAll(resp.Body)
+			if err != nil || string(body) != tt.body {
+				t.Errorf("body = %q, %v, want %q", body, err, tt.body)
+			}
+		})
+	}
+}
+
+func TestRateLimitWaitsForExhaustedQuota(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", "1")
+		io.WriteString(w, "last")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRateLimitTransport(nil, time.Minute, 100*time.Millisecond)}
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	returned := time.Now()
+
+	// The response that used up the quota comes back after the reset, and
+	// its body is still readable although the wait outlasted the timeout
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "last" || attempts != 1 {
+		t.Errorf("got %q after %d attempts, want last after 1", body, attempts)
+	}
+	if waited := returned.Sub(start); waited < 900*time.Millisecond {
+		t.Errorf("returned after %s, want the quota reset to be waited for", waited)
+	}
+}
@@ -0,0 +1,139 @@
+package platforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// restClient is a small JSON REST client for platforms without a Go SDK
+type restClient struct {
+	client    *http.Client
+	baseURL   string
+	authorize func(*http.Request)
+}
+
+// newRESTClient creates a client for an API base URL that waits out rate
+// limits for up to rateLimitWait and limits each request to timeout;
+// authorize adds credentials to each request. The timeout is applied by the
+// transport rather than the client, which would cut rate limit waits short.
+func newRESTClient(baseURL string, rateLimitWait, timeout time.Duration, authorize func(*http.Request)) *restClient {
+	return &restClient{
+		client:    &http.Client{Transport: newRateLimitTransport(nil, rateLimitWait, timeout)},
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		authorize: authorize,
+	}
+}
+
+// url builds an absolute API URL
+func (c *restClient) url(endpoint string, query url.Values) string {
+	u := c.baseURL + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// get fetches an API path and decodes the JSON response into out
+func (c *restClient) get(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(endpoint, query), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, out)
+	return err
+}
+
+// do authenticates and sends a request, decoding a JSON response into out
+// when it is non-nil
+func (c *restClient) do(req *http.Request, out interface{}) (*http.Response, error) {
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Azure DevOps answers rejected credentials with a 203 sign-in page
+	if resp.StatusCode == http.StatusNonAuthoritativeInfo {
+		return resp, &statusError{StatusCode: http.StatusUnauthorized, Message: "credentials rejected"}
+	}
+
+	if resp.StatusCode >= 300 {
+		return resp, newStatusError(resp)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// statusError is a non-2xx API response
+type statusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *statusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap maps the response onto the common platform errors
+func (e *statusError) Unwrap() error {
+	return errorForStatus(e.StatusCode, e.Message)
+}
+
+// newStatusError reads the error message out of a failed response
+func newStatusError(resp *http.Response) *statusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	// Bitbucket Cloud reports {"error": {...}}, Bitbucket Server {"errors": [...]}
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &payload) == nil {
+		switch {
+		case payload.Error.Message != "":
+			message = payload.Error.Message
+		case len(payload.Errors) > 0 && payload.Errors[0].Message != "":
+			message = payload.Errors[0].Message
+		case payload.Message != "":
+			message = payload.Message
+		}
+	}
+	if len(message) > 200 {
+		message = message[:200] + "..."
+	}
+
+	return &statusError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// isStatus reports whether err is an API response with the given status
+func isStatus(err error, status int) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.StatusCode == status
+}
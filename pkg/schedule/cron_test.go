@@ -0,0 +1,176 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// 2024-03-01 is a Friday
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", date(2024, 3, 1, 10, 7), date(2024, 3, 1, 10, 8)},
+		{"*/15 * * * *", date(2024, 3, 1, 10, 7), date(2024, 3, 1, 10, 15)},
+		{"*/15 * * * *", date(2024, 3, 1, 10, 45), date(2024, 3, 1, 11, 0)},
+		{"5/20 * * * *", date(2024, 3, 1, 10, 6), date(2024, 3, 1, 10, 25)},
+		{"5,10 * * * *", date(2024, 3, 1, 10, 7), date(2024, 3, 1, 10, 10)},
+		{"0 9-17/4 * * *", date(2024, 3, 1, 10, 0), date(2024, 3, 1, 13, 0)},
+		{"0 9-17/4 * * *", date(2024, 3, 1, 17, 0), date(2024, 3, 2, 9, 0)},
+		{"30 8-10 * * *", date(2024, 3, 1, 10, 30), date(2024, 3, 2, 8, 30)},
+		{"0 12 * * mon", date(2024, 3, 1, 10, 0), date(2024, 3, 4, 12, 0)},
+		{"0 12 * * MON-WED", date(2024, 3, 5, 12, 0), date(2024, 3, 6, 12, 0)},
+		{"0 0 * * 7", date(2024, 3, 1, 10, 0), date(2024, 3, 3, 0, 0)},
+		{"0 0 1 feb *", date(2024, 3, 1, 10, 0), date(2025, 2, 1, 0, 0)},
+		{"0 0 29 2 *", date(2024, 3, 1, 10, 0), date(2028, 2, 29, 0, 0)},
+		{"0 0 31 2 *", date(2024, 3, 1, 10, 0), time.Time{}},
+
+		// Seconds don't count towards the next minute
+		{"* * * * *", date(2024, 3, 1, 10, 7).Add(59 * time.Second), date(2024, 3, 1, 10, 8)},
+
+		// Macros
+		{"@hourly", date(2024, 3, 1, 10, 7), date(2024, 3, 1, 11, 0)},
+		{"@daily", date(2024, 3, 1, 10, 7), date(2024, 3, 2, 0, 0)},
+		{"@midnight", date(2024, 3, 1, 10, 7), date(2024, 3, 2, 0, 0)},
+		{"@weekly", date(2024, 3, 1, 10, 7), date(2024, 3, 3, 0, 0)},
+		{"@monthly", date(2024, 3, 1, 10, 7), date(2024, 4, 1, 0, 0)},
+		{"@yearly", date(2024, 3, 1, 10, 7), date(2025, 1, 1, 0, 0)},
+		{"@Daily", date(2024, 3, 1, 10, 7), date(2024, 3, 2, 0, 0)},
+
+		// Day of month and day of week both set: either one matches
+		{"0 0 13 * fri", date(2024, 3, 1, 10, 0), date(2024, 3, 8, 0, 0)},
+		{"0 0 13 * fri", date(2024, 3, 9, 10, 0), date(2024, 3, 13, 0, 0)},
+		// Only one of them set: that one has to match
+		{"0 0 13 * *", date(2024, 3, 1, 10, 0), date(2024, 3, 13, 0, 0)},
+		{"0 0 * * fri", date(2024, 3, 9, 10, 0), date(2024, 3, 15, 0, 0)},
+		{"0 0 */10 * *", date(2024, 3, 1, 10, 0), date(2024, 3, 11, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr, time.UTC)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestNextAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	edt := time.FixedZone("EDT", -4*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+
+	// Clocks go from 02:00 EST to 03:00 EDT on 2024-03-10, and from 02:00
+	// EDT back to 01:00 EST on 2024-11-03
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "hourly over the spring-forward gap",
+			expr: "0 * * * *",
+			from: time.Date(2024, 3, 10, 0, 30, 0, 0, est),
+			want: []time.Time{
+				time.Date(2024, 3, 10, 1, 0, 0, 0, est),
+				time.Date(2024, 3, 10, 3, 0, 0, 0, edt),
+			},
+		},
+		{
+			name: "daily in the spring-forward gap",
+			expr: "30 2 * * *",
+			from: time.Date(2024, 3, 9, 3, 0, 0, 0, est),
+			want: []time.Time{
+				time.Date(2024, 3, 11, 2, 30, 0, 0, edt),
+			},
+		},
+		{
+			name: "daily after the spring-forward gap",
+			expr: "0 0 * * *",
+			from: time.Date(2024, 3, 9, 12, 0, 0, 0, est),
+			want: []time.Time{
+				time.Date(2024, 3, 10, 0, 0, 0, 0, est),
+				time.Date(2024, 3, 11, 0, 0, 0, 0, edt),
+			},
+		},
+		{
+			name: "daily in the fall-back repeat",
+			expr: "30 1 * * *",
+			from: time.Date(2024, 11, 3, 0, 45, 0, 0, edt),
+			want: []time.Time{
+				time.Date(2024, 11, 3, 1, 30, 0, 0, edt),
+				time.Date(2024, 11, 3, 1, 30, 0, 0, est),
+				time.Date(2024, 11, 4, 1, 30, 0, 0, est),
+			},
+		},
+		{
+			name: "every 20 minutes over the fall-back repeat",
+			expr: "*/20 1 * * *",
+			from: time.Date(2024, 11, 3, 1, 45, 0, 0, edt),
+			want: []time.Time{
+				time.Date(2024, 11, 3, 1, 0, 0, 0, est),
+				time.Date(2024, 11, 3, 1, 20, 0, 0, est),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			from := tt.from
+			for i, want := range tt.want {
+				got := s.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("run %d: Next(%s) = %s, want %s", i+1, from, got, want.In(ny))
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@often",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"x * * * *",
+		"1- * * * *",
+		"* * * foo *",
+		"* * * * funday",
+		"1,,2 * * * *",
+	} {
+		if _, err := Parse(expr, time.UTC); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
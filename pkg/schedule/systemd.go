@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// OnCalendar translates the schedule into systemd OnCalendar= expressions.
+// Cron fires when either a restricted day of month or day of week matches,
+// which systemd can't say in one expression, so that case yields two. A
+// schedule that never fires is an error; systemd would take it silently.
+func (s *Schedule) OnCalendar() ([]string, error) {
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", s.expr)
+	}
+
+	timePart := fmt.Sprintf("%s:%s:00", calendarList(s.hour, 0, 23, 2), calendarList(s.minute, 0, 59, 2))
+	month := calendarList(s.month, 1, 12, 2)
+	zone := ""
+	if s.loc != time.Local {
+		zone = " " + s.loc.String()
+	}
+
+	byDay := fmt.Sprintf("*-%s-%s %s%s", month, calendarList(s.dom, 1, 31, 2), timePart, zone)
+	byWeekday := fmt.Sprintf("%s *-%s-* %s%s", weekdayList(s.dow), month, timePart, zone)
+
+	switch {
+	case s.domRestricted && s.dowRestricted:
+		return []string{byDay, byWeekday}, nil
+	case s.dowRestricted:
+		return []string{byWeekday}, nil
+	default:
+		return []string{byDay}, nil
+	}
+}
+
+// calendarList renders a bit set as "*", or as a comma separated list where
+// runs of three or more values become "a..b" ranges
+func calendarList(bits uint64, min, max, width int) string {
+	all := true
+	for v := min; v <= max; v++ {
+		if bits&(1<<uint(v)) == 0 {
+			all = false
+			break
+		}
+	}
+	if all {
+		return "*"
+	}
+
+	var parts []string
+	for v := min; v <= max; v++ {
+		if bits&(1<<uint(v)) == 0 {
+			continue
+		}
+
+		end := v
+		for end < max && bits&(1<<uint(end+1)) != 0 {
+			end++
+		}
+
+		switch {
+		case end-v >= 2:
+			parts = append(parts, fmt.Sprintf("%0*d..%0*d", width, v, width, end))
+		case end > v:
+			parts = append(parts, fmt.Sprintf("%0*d,%0*d", width, v, width, end))
+		default:
+			parts = append(parts, fmt.Sprintf("%0*d", width, v))
+		}
+		v = end
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// weekdayList renders a day-of-week bit set as systemd weekday names
+func weekdayList(bits uint64) string {
+	var days []string
+	// systemd weeks start on Monday
+	for _, d := range []int{1, 2, 3, 4, 5, 6, 0} {
+		if bits&(1<<uint(d)) != 0 {
+			days = append(days, weekdayNames[d])
+		}
+	}
+	return strings.Join(days, ",")
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnCalendar(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // expressions joined with "; "
+	}{
+		{"0 18 * * *", "*-*-* 18:00:00"},
+		{"*/15 9-17 * * *", "*-*-* 09..17:00,15,30,45:00"},
+		{"30 8,12 * * *", "*-*-* 08,12:30:00"},
+		{"0 0 1-10/3 * *", "*-*-01,04,07,10 00:00:00"},
+		{"0 6 * jan-mar *", "*-01..03-* 06:00:00"},
+		{"0 9 * * mon-fri", "Mon,Tue,Wed,Thu,Fri *-*-* 09:00:00"},
+		{"0 9 * * 0,7", "Sun *-*-* 09:00:00"},
+		{"@weekly", "Sun *-*-* 00:00:00"},
+		{"@hourly", "*-*-* *:00:00"},
+		{"0 0 1,15 * fri", "*-*-01,15 00:00:00; Fri *-*-* 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr, time.Local)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		calendars, err := s.OnCalendar()
+		if err != nil {
+			t.Errorf("%q.OnCalendar(): %v", tt.expr, err)
+			continue
+		}
+		if got := strings.Join(calendars, "; "); got != tt.want {
+			t.Errorf("%q.OnCalendar() = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestOnCalendarZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	s, err := Parse("30 2 * * *", ny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendars, err := s.OnCalendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(calendars, "; "), "*-*-* 02:30:00 America/New_York"; got != want {
+		t.Errorf("OnCalendar() = %q, want %q", got, want)
+	}
+}
+
+func TestOnCalendarNeverFires(t *testing.T) {
+	s, err := Parse("0 0 31 2 *", time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calendars, err := s.OnCalendar(); err == nil {
+		t.Errorf("OnCalendar() = %q, want an error", calendars)
+	}
+}
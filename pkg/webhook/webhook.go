@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+const (
+	// maxPayloadSize bounds request bodies; GitHub caps payloads at 25 MB
+	maxPayloadSize = 25 << 20
+
+	// pushCommitLimit is how many commits GitLab lists in a push payload.
+	// GitHub doesn't say when it leaves commits out, so its pushes listing
+	// this many or more are taken to be truncated too.
+	pushCommitLimit = 20
+)
+
+// PushEvent is a push reported by a platform webhook
+type PushEvent struct {
+	Platform      platforms.PlatformType
+	Host          string // host name the repository is served from
+	Repository    string // full name, e.g. "group/project"
+	Ref           string
+	DefaultBranch string
+	Commits       []platforms.Commit
+
+	// Truncated is set when the payload may not list every pushed commit
+	Truncated bool
+}
+
+// OnDefaultBranch reports whether the push updated the repository's default
+// branch. Pushes to other branches and to tags aren't part of its history.
+func (e PushEvent) OnDefaultBranch() bool {
+	return e.DefaultBranch != "" && e.Ref == "refs/heads/"+e.DefaultBranch
+}
+
+// Handler receives GitHub push and GitLab Push Hook webhooks, verifies their
+// secrets and hands the pushed commits to OnPush. Events from a platform
+// without a configured secret are rejected.
+type Handler struct {
+	GitHubSecret string
+	GitLabToken  string
+	OnPush       func(PushEvent) error
+	Logf         func(format string, args ...interface{})
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	var event *PushEvent
+	switch {
+	case r.Header.Get("X-GitHub-Event") != "":
+		event, err = h.github(r, body)
+	case r.Header.Get("X-Gitlab-Event") != "":
+		event, err = h.gitlab(r, body)
+	default:
+		err = &httpError{http.StatusBadRequest, "unrecognized webhook (expected a GitHub or GitLab push event)"}
+	}
+
+	if err != nil {
+		status := http.StatusBadRequest
+		if he, ok := err.(*httpError); ok {
+			status = he.status
+		}
+		h.logf("❌ Rejected webhook from %s: %v", r.RemoteAddr, err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// Verified but not a push (e.g. GitHub's ping)
+	if event == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := h.OnPush(*event); err != nil {
+		h.logf("❌ %s push to %s not accepted: %v", event.Platform, event.Repository, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "accepted %d commits\n", len(event.Commits))
+}
+
+// github verifies and parses a GitHub webhook
+func (h *Handler) github(r *http.Request, body []byte) (*PushEvent, error) {
+	if h.GitHubSecret == "" {
+		return nil, &httpError{http.StatusForbidden, "GitHub webhooks are not enabled (no secret configured)"}
+	}
+
+	signature := strings.TrimPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	expected, err := hex.DecodeString(signature)
+	if err != nil || signature == "" {
+		return nil, &httpError{http.StatusUnauthorized, "missing or malformed X-Hub-Signature-256"}
+	}
+
+	mac := hmac.New(sha256.New, []byte(h.GitHubSecret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, &httpError{http.StatusUnauthorized, "invalid X-Hub-Signature-256"}
+	}
+
+	if r.Header.Get("X-GitHub-Event") != "push" {
+		return nil, nil
+	}
+
+	var payload struct {
+		Ref        string `json:"ref"`
+		Repository struct {
+			FullName      string `json:"full_name"`
+			HTMLURL       string `json:"html_url"`
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+		Commits []struct {
+			ID        string    `json:"id"`
+			Message   string    `json:"message"`
+			Timestamp time.Time `json:"timestamp"`
+			URL       string    `json:"url"`
+			Author    pushUser  `json:"author"`
+			Committer pushUser  `json:"committer"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitHub push payload: %w", err)
+	}
+
+	event := &PushEvent{
+		Platform:      platforms.PlatformGitHub,
+		Host:          hostName(payload.Repository.HTMLURL),
+		Repository:    payload.Repository.FullName,
+		Ref:           payload.Ref,
+		DefaultBranch: payload.Repository.DefaultBranch,
+		Truncated:     len(payload.Commits) >= pushCommitLimit,
+	}
+	for _, c := range payload.Commits {
+		event.Commits = append(event.Commits, platforms.Commit{
+			SHA:       c.ID,
+			Message:   c.Message,
+			Author:    c.Author.author(),
+			Committer: c.Committer.author(),
+			Date:      c.Timestamp,
+			URL:       c.URL,
+			Repo:      payload.Repository.FullName,
+			Platform:  string(platforms.PlatformGitHub),
+		})
+	}
+
+	return event, nil
+}
+
+// gitlab verifies and parses a GitLab webhook
+func (h *Handler) gitlab(r *http.Request, body []byte) (*PushEvent, error) {
+	if h.GitLabToken == "" {
+		return nil, &httpError{http.StatusForbidden, "GitLab webhooks are not enabled (no token configured)"}
+	}
+
+	token := r.Header.Get("X-Gitlab-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.GitLabToken)) != 1 {
+		return nil, &httpError{http.StatusUnauthorized, "invalid X-Gitlab-Token"}
+	}
+
+	if r.Header.Get("X-Gitlab-Event") != "Push Hook" {
+		return nil, nil
+	}
+
+	var payload struct {
+		Ref               string `json:"ref"`
+		TotalCommitsCount int    `json:"total_commits_count"`
+		Project           struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+			WebURL            string `json:"web_url"`
+			DefaultBranch     string `json:"default_branch"`
+		} `json:"project"`
+		Commits []struct {
+			ID        string    `json:"id"`
+			Message   string    `json:"message"`
+			Timestamp time.Time `json:"timestamp"`
+			URL       string    `json:"url"`
+			Author    pushUser  `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitLab push payload: %w", err)
+	}
+
+	event := &PushEvent{
+		Platform:      platforms.PlatformGitLab,
+		Host:          hostName(payload.Project.WebURL),
+		Repository:    payload.Project.PathWithNamespace,
+		Ref:           payload.Ref,
+		DefaultBranch: payload.Project.DefaultBranch,
+		Truncated:     payload.TotalCommitsCount > len(payload.Commits),
+	}
+	for _, c := range payload.Commits {
+		// GitLab push payloads only carry the author
+		event.Commits = append(event.Commits, platforms.Commit{
+			SHA:       c.ID,
+			Message:   c.Message,
+			Author:    c.Author.author(),
+			Committer: c.Author.author(),
+			Date:      c.Timestamp,
+			URL:       c.URL,
+			Repo:      payload.Project.PathWithNamespace,
+			Platform:  string(platforms.PlatformGitLab),
+		})
+	}
+
+	return event, nil
+}
+
+func (h *Handler) logf(format string, args ...interface{}) {
+	if h.Logf != nil {
+		h.Logf(format, args...)
+	}
+}
+
+// hostName returns the lower case host name of a repository URL, or "" if
+// it can't be parsed
+func hostName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// pushUser is an author or committer in a push payload
+type pushUser struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"` // GitHub only
+}
+
+func (u pushUser) author() platforms.Author {
+	return platforms.Author{Name: u.Name, Email: u.Email, Username: u.Username}
+}
+
+// httpError is a request error with the status code to answer it with
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
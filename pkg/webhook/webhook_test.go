@@ -0,0 +1,245 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ja-Crispy/git-activity-mirror/pkg/platforms"
+)
+
+const (
+	githubPush = `{
+		"ref": "refs/heads/main",
+		"repository": {"full_name": "jane/repo", "html_url": "https://github.com/jane/repo", "default_branch": "main"},
+		"commits": [
+			{
+				"id": "a1",
+				"message": "Fix parser",
+				"timestamp": "2024-03-01T10:00:00+01:00",
+				"url": "https://github.com/jane/repo/commit/a1",
+				"author": {"name": "Jane Doe", "email": "jane@example.com", "username": "jane"},
+				"committer": {"name": "GitHub", "email": "noreply@github.com", "username": "web-flow"}
+			}
+		]
+	}`
+
+	gitlabPush = `{
+		"ref": "refs/heads/develop",
+		"total_commits_count": 25,
+		"project": {"path_with_namespace": "group/project", "web_url": "https://GitLab.Example.com/group/project", "default_branch": "main"},
+		"commits": [
+			{
+				"id": "b2",
+				"message": "Add tests",
+				"timestamp": "2024-03-02T09:30:00Z",
+				"url": "https://gitlab.example.com/group/project/-/commit/b2",
+				"author": {"name": "Jane Doe", "email": "jane@example.com"}
+			}
+		]
+	}`
+)
+
+// sign returns the X-Hub-Signature-256 of a body
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// serve sends a webhook request to a handler
+func serve(h *Handler, headers map[string]string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		status  int
+		pushed  bool
+	}{
+		{
+			name:    "GitHub push",
+			headers: map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("secret", githubPush)},
+			body:    githubPush,
+			status:  http.StatusAccepted,
+			pushed:  true,
+		},
+		{
+			name:    "GitHub bad signature",
+			headers: map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("other", githubPush)},
+			body:    githubPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "GitHub malformed signature",
+			headers: map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=not-hex"},
+			body:    githubPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "GitHub missing signature",
+			headers: map[string]string{"X-GitHub-Event": "push"},
+			body:    githubPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "GitHub signature over another body",
+			headers: map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("secret", "{}")},
+			body:    githubPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "GitHub ping",
+			headers: map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": sign("secret", `{"zen":"hi"}`)},
+			body:    `{"zen":"hi"}`,
+			status:  http.StatusNoContent,
+		},
+		{
+			name:    "GitLab push",
+			headers: map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "token"},
+			body:    gitlabPush,
+			status:  http.StatusAccepted,
+			pushed:  true,
+		},
+		{
+			name:    "GitLab wrong token",
+			headers: map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "wrong"},
+			body:    gitlabPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "GitLab missing token",
+			headers: map[string]string{"X-Gitlab-Event": "Push Hook"},
+			body:    gitlabPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "GitLab tag push",
+			headers: map[string]string{"X-Gitlab-Event": "Tag Push Hook", "X-Gitlab-Token": "token"},
+			body:    `{"ref":"refs/tags/v1"}`,
+			status:  http.StatusNoContent,
+		},
+		{
+			name:    "GitLab invalid payload",
+			headers: map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "token"},
+			body:    `{"commits": 1}`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:   "unknown platform",
+			body:   githubPush,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushed bool
+			h := &Handler{
+				GitHubSecret: "secret",
+				GitLabToken:  "token",
+				OnPush: func(event PushEvent) error {
+					pushed = true
+					return nil
+				},
+			}
+
+			rec := serve(h, tt.headers, tt.body)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.status, strings.TrimSpace(rec.Body.String()))
+			}
+			if pushed != tt.pushed {
+				t.Errorf("pushed = %v, want %v", pushed, tt.pushed)
+			}
+		})
+	}
+}
+
+func TestHandlerRequiresSecrets(t *testing.T) {
+	h := &Handler{OnPush: func(PushEvent) error {
+		t.Error("push accepted without a secret")
+		return nil
+	}}
+
+	for _, headers := range []map[string]string{
+		{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("", githubPush)},
+		{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": ""},
+	} {
+		if rec := serve(h, headers, githubPush); rec.Code != http.StatusForbidden {
+			t.Errorf("%v: status = %d, want %d", headers, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestHandlerRejectsOtherMethods(t *testing.T) {
+	h := &Handler{GitHubSecret: "secret", OnPush: func(PushEvent) error { return nil }}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandlerParsesPushes(t *testing.T) {
+	var events []PushEvent
+	h := &Handler{
+		GitHubSecret: "secret",
+		GitLabToken:  "token",
+		OnPush: func(event PushEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+
+	serve(h, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("secret", githubPush)}, githubPush)
+	serve(h, map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "token"}, gitlabPush)
+	if len(events) != 2 {
+		t.Fatalf("got %d push events, want 2", len(events))
+	}
+
+	github := events[0]
+	if github.Platform != platforms.PlatformGitHub || github.Host != "github.com" || github.Repository != "jane/repo" {
+		t.Errorf("GitHub push to %s %s %s", github.Platform, github.Host, github.Repository)
+	}
+	if !github.OnDefaultBranch() || github.Truncated {
+		t.Errorf("GitHub push on default branch: %v, truncated: %v", github.OnDefaultBranch(), github.Truncated)
+	}
+	if len(github.Commits) != 1 {
+		t.Fatalf("GitHub push has %d commits, want 1", len(github.Commits))
+	}
+	commit := github.Commits[0]
+	if commit.SHA != "a1" || commit.Message != "Fix parser" || commit.Repo != "jane/repo" {
+		t.Errorf("GitHub commit = %+v", commit)
+	}
+	if commit.Author.Username != "jane" || commit.Author.Email != "jane@example.com" || commit.Committer.Username != "web-flow" {
+		t.Errorf("GitHub commit by %+v, committed by %+v", commit.Author, commit.Committer)
+	}
+	if commit.Date.Unix() != 1709283600 {
+		t.Errorf("GitHub commit dated %s", commit.Date)
+	}
+
+	gitlab := events[1]
+	if gitlab.Platform != platforms.PlatformGitLab || gitlab.Host != "gitlab.example.com" || gitlab.Repository != "group/project" {
+		t.Errorf("GitLab push to %s %s %s", gitlab.Platform, gitlab.Host, gitlab.Repository)
+	}
+	if gitlab.OnDefaultBranch() || !gitlab.Truncated {
+		t.Errorf("GitLab push on default branch: %v, truncated: %v", gitlab.OnDefaultBranch(), gitlab.Truncated)
+	}
+	if len(gitlab.Commits) != 1 || gitlab.Commits[0].Committer != gitlab.Commits[0].Author {
+		t.Errorf("GitLab commits = %+v", gitlab.Commits)
+	}
+}